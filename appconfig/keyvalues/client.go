@@ -1,13 +1,17 @@
 package keyvalues
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/adal"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
 )
 
@@ -15,111 +19,389 @@ const (
 	defaultContentType     = "application/vnd.microsoft.appconfig.kv+json"
 	keyVaultRefContentType = "application/vnd.microsoft.appconfig.keyvaultref+json;charset=utf-8"
 	apiVersion             = "1.0"
+	latestAPIVersion       = "2023-11-01"
 )
 
 // Client is an interface with all methods to
-// manage App Configuration Key Values
+// manage App Configuration Key Values.
+//
+// The methods taking a context.Context attach it to the outgoing requests,
+// so callers can cancel them and propagate deadlines. ListKeyValues,
+// GetKeyValue, CreateOrUpdateKeyValue and DeleteKeyValue use
+// context.Background(), see their WithContext variants.
 type Client interface {
 	// ListKeyValues returns an array of App Configuration KeyValues. The list
-	// of KeyValues are filtered by the provided Key and/or Label.
+	// of KeyValues are filtered by the provided Key and/or Label. All result
+	// pages are fetched by following the continuation links.
 	//
-	// Optional: Key; Label (if not specified, it implies any Key/Label).
+	// Optional: Key; Label; Keys; Labels (if not specified, it implies any
+	// Key/Label); Tags; Snapshot; Select; AsOf; NextLink
 	ListKeyValues(ListKeyValuesArgs) (KeyValues, error)
 
+	// ListKeyValuesWithContext is ListKeyValues sending the requests
+	// in ctx.
+	ListKeyValuesWithContext(context.Context, ListKeyValuesArgs) (KeyValues, error)
+
+	// NewListKeyValuesPager returns a KeyValuesPager that lazily fetches the
+	// pages of KeyValues filtered by the provided Key and/or Label.
+	//
+	// Optional: Key; Label; Keys; Labels (if not specified, it implies any
+	// Key/Label); Tags; Snapshot; Select; AsOf; NextLink
+	NewListKeyValuesPager(ListKeyValuesArgs) KeyValuesPager
+
 	// GetKeyValue gets an App Configuration Key-Value.
 	GetKeyValue(key, label string) (KeyValue, error)
 
+	// GetKeyValueWithContext is GetKeyValue sending the request in ctx.
+	GetKeyValueWithContext(ctx context.Context, key, label string) (KeyValue, error)
+
+	// GetKeyValueWithArgs gets an App Configuration Key-Value.
+	//
+	// Required parameters: Key
+	//
+	// Optional parameters: Label; IfNoneMatch; Select; AsOf
+	GetKeyValueWithArgs(context.Context, GetKeyValueArgs) (KeyValue, error)
+
 	// CreateOrUpdateKeyValue create/update an App Configuration Key-Value.
 	//
 	// Required parameters: Key; Value
 	//
-	// Optional parameters: Label; ContentType; Tags; IsSecret
+	// Optional parameters: Label; ContentType; Tags; IsSecret; IfMatch;
+	// IfNoneMatch
 	CreateOrUpdateKeyValue(CreateOrUpdateKeyValueArgs) (KeyValue, error)
 
+	// CreateOrUpdateKeyValueWithContext is CreateOrUpdateKeyValue sending
+	// the request in ctx.
+	CreateOrUpdateKeyValueWithContext(context.Context, CreateOrUpdateKeyValueArgs) (KeyValue, error)
+
 	// DeleteKeyValue deletes an App Configuration Key-Value.
 	DeleteKeyValue(key, label string) error
+
+	// DeleteKeyValueWithContext is DeleteKeyValue sending the request
+	// in ctx.
+	DeleteKeyValueWithContext(ctx context.Context, key, label string) error
+
+	// DeleteKeyValueWithArgs deletes an App Configuration Key-Value.
+	//
+	// Required parameters: Key
+	//
+	// Optional parameters: Label; IfMatch
+	DeleteKeyValueWithArgs(context.Context, DeleteKeyValueArgs) error
+
+	// ListRevisions returns the revision history of App Configuration
+	// KeyValues, filtered by the provided Key and/or Label. All result pages
+	// are fetched by following the continuation links.
+	//
+	// Optional: Key; Label (if not specified, it implies any Key/Label);
+	// Select; AsOf
+	ListRevisions(context.Context, ListRevisionsArgs) (KeyValues, error)
+
+	// NewListRevisionsPager returns a KeyValuesPager that lazily fetches the
+	// pages of the revision history of App Configuration KeyValues.
+	//
+	// Optional: Key; Label (if not specified, it implies any Key/Label);
+	// Select; AsOf
+	NewListRevisionsPager(ListRevisionsArgs) KeyValuesPager
+
+	// ListKeys returns the distinct App Configuration keys, filtered by the
+	// provided name filter (if empty, it implies any key). All result pages
+	// are fetched by following the continuation links.
+	ListKeys(ctx context.Context, nameFilter string) (Keys, error)
+
+	// ListLabels returns the distinct App Configuration labels, filtered by
+	// the provided name filter (if empty, it implies any label). All result
+	// pages are fetched by following the continuation links.
+	ListLabels(ctx context.Context, nameFilter string) (Labels, error)
+
+	// CreateSnapshot creates an App Configuration Snapshot and waits for its
+	// creation operation to complete, returning the ready Snapshot.
+	//
+	// Required parameters: Name; Filters
+	//
+	// Optional parameters: CompositionType; RetentionPeriod; Tags; PollInterval
+	CreateSnapshot(context.Context, CreateSnapshotArgs) (Snapshot, error)
+
+	// GetSnapshot gets an App Configuration Snapshot.
+	GetSnapshot(ctx context.Context, name string) (Snapshot, error)
+
+	// ListSnapshots returns an array of App Configuration Snapshots, filtered
+	// by the provided Name and/or Status. All result pages are fetched by
+	// following the continuation links.
+	//
+	// Optional: Name (if not specified, it implies any Snapshot); Status
+	ListSnapshots(context.Context, ListSnapshotsArgs) (Snapshots, error)
+
+	// ArchiveSnapshot archives a ready App Configuration Snapshot. An archived
+	// Snapshot expires once its retention period is over.
+	ArchiveSnapshot(ctx context.Context, name string) (Snapshot, error)
+
+	// RecoverSnapshot recovers an archived App Configuration Snapshot,
+	// making it ready again.
+	RecoverSnapshot(ctx context.Context, name string) (Snapshot, error)
+
+	// LockKeyValue locks an App Configuration Key-Value, making it
+	// read-only, and returns the updated Key-Value.
+	LockKeyValue(ctx context.Context, key, label string) (KeyValue, error)
+
+	// UnlockKeyValue unlocks an App Configuration Key-Value and returns the
+	// updated Key-Value.
+	UnlockKeyValue(ctx context.Context, key, label string) (KeyValue, error)
+
+	// UpdateSyncToken merges a sync token, e.g. received from an Event Grid
+	// notification, so the following requests observe at least its
+	// changes. The sync tokens of the responses are merged automatically.
+	UpdateSyncToken(token string) error
 }
 
 // ClientImpl implements the Client interface
+//
+// RetryPolicy configures the retries of idempotent requests. Requests are
+// not retried by default, see DefaultRetryPolicy. Logger, if set, receives
+// the request logs. APIVersion, if set, overrides the api-version of every
+// request. Policies wrap every request, before the retries, and
+// PerRetryPolicies wrap every attempt, after the retries. Tracer, if
+// set, traces the operations. Metrics, if set, records the measures of the
+// requests. RedactedKeys match the keys whose values are not logged.
 type ClientImpl struct {
 	autorest.Client
-	Endpoint string
+	Endpoint         string
+	RetryPolicy      RetryPolicy
+	Logger           Logger
+	RedactedKeys     []*regexp.Regexp
+	APIVersion       string
+	Policies         []Policy
+	PerRetryPolicies []Policy
+	Tracer           Tracer
+	Metrics          MetricsRecorder
+
+	syncTokens syncTokens
 }
 
 // NewClientAzureAD creates a Client configured from Azure AD credentials.
-func NewClientAzureAD(args NewClientAzureADArgs) (Client, error) {
+func NewClientAzureAD(args NewClientAzureADArgs, opts ...ClientOption) (Client, error) {
+	aadEndpoint, audience := args.Cloud.resolve(args.AADEndpoint, args.ResourceEndpoint)
 	creds := auth.NewClientCredentialsConfig(args.ClientID, args.ClientSecret, args.TenantID)
-	creds.Resource = args.ResourceEndpoint
-	if args.AADEndpoint != "" {
-		creds.AADEndpoint = args.AADEndpoint
+	creds.Resource = audience
+	creds.AADEndpoint = aadEndpoint
+
+	auth, err := creds.Authorizer()
+	if err != nil {
+		return nil, err
 	}
 
+	return NewClient(args.ResourceEndpoint, auth, opts...), nil
+}
+
+// NewClientManagedIdentity creates a Client configured from an Azure
+// Managed Identity.
+func NewClientManagedIdentity(args NewClientManagedIdentityArgs, opts ...ClientOption) (Client, error) {
+	_, audience := args.Cloud.resolve("", args.ResourceEndpoint)
+	creds := auth.NewMSIConfig()
+	creds.Resource = audience
+	creds.ClientID = args.ClientID
+
 	auth, err := creds.Authorizer()
 	if err != nil {
 		return nil, err
 	}
+	return NewClient(args.ResourceEndpoint, auth, opts...), nil
+}
 
-	return NewClient(args.ResourceEndpoint, auth), nil
+// NewClientWorkloadIdentity creates a Client configured from an Azure AD
+// Workload Identity, exchanging the federated token file for access tokens.
+func NewClientWorkloadIdentity(args NewClientWorkloadIdentityArgs, opts ...ClientOption) (Client, error) {
+	aadEndpoint, audience := args.Cloud.resolve(args.AADEndpoint, args.ResourceEndpoint)
+	oauthConfig, err := adal.NewOAuthConfig(aadEndpoint, args.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := &federatedTokenSecret{tokenFilePath: args.TokenFilePath}
+	spt, err := adal.NewServicePrincipalTokenWithSecret(*oauthConfig, args.ClientID, audience, secret)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(args.ResourceEndpoint, autorest.NewBearerAuthorizer(spt), opts...), nil
 }
 
 // NewClientCli creates a Client configured from Azure CLI 2.0.
-func NewClientCli(endpoint string) (Client, error) {
-	auth, err := auth.NewAuthorizerFromCLIWithResource(endpoint)
+func NewClientCli(endpoint string, opts ...ClientOption) (Client, error) {
+	return newClientCli(endpoint, endpoint, opts...)
+}
+
+// newClientCli creates a Client configured from Azure CLI 2.0, requesting
+// tokens for the audience.
+func newClientCli(endpoint, audience string, opts ...ClientOption) (Client, error) {
+	auth, err := auth.NewAuthorizerFromCLIWithResource(audience)
 	if err != nil {
 		return nil, err
 	}
-	return NewClient(endpoint, auth), nil
+	return NewClient(endpoint, auth, opts...), nil
+}
+
+// NewClientFromConnectionString creates a Client configured from an
+// App Configuration access key connection string, signing each request
+// with the HMAC-SHA256 scheme.
+//
+// Example: Endpoint=https://my-config.azconfig.io;Id=my-id;Secret=my-secret
+func NewClientFromConnectionString(connectionString string, opts ...ClientOption) (Client, error) {
+	cs, err := parseConnectionString(connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := NewHMACAuthorizer(cs.ID, cs.Secret)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(cs.Endpoint, auth, opts...), nil
 }
 
 // NewClient creates an instance of the Client.
-func NewClient(endpoint string, authorizer autorest.Authorizer) Client {
+func NewClient(endpoint string, authorizer autorest.Authorizer, opts ...ClientOption) Client {
+	o := newClientOptions(opts)
+
 	client := autorest.NewClientWithUserAgent(autorest.UserAgent())
 	client.Authorizer = authorizer
+	if o.userAgent != "" {
+		_ = client.AddToUserAgent(o.userAgent)
+	}
+	if sender := o.sender(); sender != nil {
+		client.Sender = sender
+	}
 
 	return &ClientImpl{
-		Client:   client,
-		Endpoint: endpoint,
+		Client:           client,
+		Endpoint:         endpoint,
+		RetryPolicy:      o.retryPolicy,
+		Logger:           o.logger,
+		RedactedKeys:     o.redactedKeys,
+		APIVersion:       o.apiVersion,
+		Policies:         o.policies,
+		PerRetryPolicies: o.perRetryPolicies,
+		Tracer:           o.tracer,
+		Metrics:          o.metrics,
 	}
 }
 
 // ListKeyValues returns an array of App Configuration KeyValues. The list
-// of KeyValues are filtered by the provided Key and/or Label.
+// of KeyValues are filtered by the provided Key and/or Label. All result
+// pages are fetched by following the continuation links.
 //
-// Optional: Key; Label (if not specified, it implies any Key/Label).
+// Optional: Key; Label; Keys; Labels (if not specified, it implies any
+// Key/Label); Tags; Snapshot; Select; AsOf; NextLink
 func (client *ClientImpl) ListKeyValues(args ListKeyValuesArgs) (KeyValues, error) {
-	if args.Key == "" {
-		args.Key = "*"
+	return client.ListKeyValuesWithContext(context.Background(), args)
+}
+
+// ListKeyValuesWithContext is ListKeyValues sending the requests in ctx.
+func (client *ClientImpl) ListKeyValuesWithContext(ctx context.Context, args ListKeyValuesArgs) (result KeyValues, err error) {
+	ctx, op := client.startOperation(ctx, "appconfig.ListKeyValues", listKeyValuesAttributes(args)...)
+	defer func() {
+		op.set(AttributeItemCount, len(result.Items))
+		op.end(err)
+	}()
+
+	return collectKeyValues(ctx, client.NewListKeyValuesPager(args))
+}
+
+// NewListKeyValuesPager returns a KeyValuesPager that lazily fetches the
+// pages of KeyValues filtered by the provided Key and/or Label.
+//
+// Optional: Key; Label; Keys; Labels (if not specified, it implies any
+// Key/Label); Tags; Snapshot; Select; AsOf; NextLink
+func (client *ClientImpl) NewListKeyValuesPager(args ListKeyValuesArgs) KeyValuesPager {
+	attributes := listKeyValuesAttributes(args)
+	key := joinFilters(args.Key, args.Keys)
+	if key == "" {
+		key = "*"
 	}
-	if args.Label == "" {
-		args.Label = "*"
+	label := joinFilters(args.Label, args.Labels)
+	if label == "" {
+		label = "*"
 	}
 
-	req, err := client.createListRequest(args.Label, args.Key, autorest.AsGet())
-	if err != nil {
-		return KeyValues{}, err
+	decorators := append(withSelect(args.Select), withAcceptDatetime(args.AsOf)...)
+	if args.NextLink != "" {
+		return newKeyValuesPager(client, "appconfig.ListKeyValues", attributes, func(ctx context.Context) (*http.Request, error) {
+			return client.createNextRequest(ctx, args.NextLink, decorators...)
+		}, decorators...)
+	}
+	if args.Snapshot != "" {
+		if key != "*" || label != "*" || len(args.Tags) > 0 {
+			return newKeyValuesPager(client, "appconfig.ListKeyValues", attributes, func(context.Context) (*http.Request, error) {
+				return nil, ErrSnapshotFilters
+			})
+		}
+		query := map[string]interface{}{
+			"snapshot":    autorest.Encode("query", args.Snapshot),
+			"api-version": latestAPIVersion,
+		}
+		return newKeyValuesPager(client, "appconfig.ListKeyValues", attributes, func(ctx context.Context) (*http.Request, error) {
+			return client.createQueryRequest(ctx, "/kv", query, append(decorators, autorest.AsGet())...)
+		}, decorators...)
+	}
+
+	// The continuation links carry the tags filter, and its decorators
+	// unescape the captured values when run, so they are built for the
+	// first request only.
+	return newKeyValuesPager(client, "appconfig.ListKeyValues", attributes, func(ctx context.Context) (*http.Request, error) {
+		first := append(append(decorators, withTags(args.Tags)...), autorest.AsGet())
+		return client.createListRequest(ctx, "/kv", label, key, first...)
+	}, decorators...)
+}
+
+// listKeyValuesAttributes returns the Span attributes of a KeyValues
+// listing.
+func listKeyValuesAttributes(args ListKeyValuesArgs) []string {
+	return []string{
+		AttributeKey, joinFilters(args.Key, args.Keys),
+		AttributeLabel, joinFilters(args.Label, args.Labels),
+		AttributeSnapshot, args.Snapshot,
 	}
+}
 
+// listKeyValuesPage sends a list request and decodes a single result page.
+func (client *ClientImpl) listKeyValuesPage(req *http.Request) (KeyValues, error) {
 	response, err := client.sendRequest(req)
 	if err != nil {
 		return KeyValues{}, err
 	}
 
-	var result KeyValues
-	if err = getJSON(response, &result); err != nil {
+	var page KeyValues
+	if err = getJSON(response, &page); err != nil {
 		return KeyValues{}, err
 	}
-	return result, nil
+	page.NextLink = getNextLink(response)
+	return page, nil
 }
 
 // GetKeyValue gets an App Configuration Key-Value.
 func (client *ClientImpl) GetKeyValue(key, label string) (KeyValue, error) {
-	result := KeyValue{}
+	return client.GetKeyValueWithContext(context.Background(), key, label)
+}
 
-	req, err := client.createRequest(
-		label,
-		url.QueryEscape(key),
-		autorest.AsGet(),
-	)
+// GetKeyValueWithContext is GetKeyValue sending the request in ctx.
+func (client *ClientImpl) GetKeyValueWithContext(ctx context.Context, key, label string) (KeyValue, error) {
+	return client.GetKeyValueWithArgs(ctx, GetKeyValueArgs{Key: key, Label: label})
+}
+
+// GetKeyValueWithArgs gets an App Configuration Key-Value.
+//
+// Required parameters: Key
+//
+// Optional parameters: Label; IfNoneMatch; Select; AsOf
+func (client *ClientImpl) GetKeyValueWithArgs(ctx context.Context, args GetKeyValueArgs) (result KeyValue, err error) {
+	ctx, op := client.startOperation(ctx, "appconfig.GetKeyValue", AttributeKey, args.Key, AttributeLabel, args.Label)
+	defer func() { op.end(err) }()
+
+	decorators := append(withSelect(args.Select), withAcceptDatetime(args.AsOf)...)
+	decorators = append(decorators, autorest.AsGet())
+	if args.IfNoneMatch != "" {
+		decorators = append(decorators, autorest.WithHeader("If-None-Match", quoteETag(args.IfNoneMatch)))
+	}
+
+	req, err := client.createRequest(ctx, args.Label, url.QueryEscape(args.Key), decorators...)
 	if err != nil {
 		return result, err
 	}
@@ -128,6 +410,10 @@ func (client *ClientImpl) GetKeyValue(key, label string) (KeyValue, error) {
 	if err != nil {
 		return result, err
 	}
+	if response.StatusCode == http.StatusNotModified {
+		response.Body.Close()
+		return result, ErrNotModified
+	}
 
 	if err = getJSON(response, &result); err != nil {
 		return result, err
@@ -139,9 +425,17 @@ func (client *ClientImpl) GetKeyValue(key, label string) (KeyValue, error) {
 // CreateOrUpdateKeyValue create/update an App Configuration Key-Value.
 //
 // Required parameters: Key; Value
-// Optional parameters: Label; ContentType; Tags; IsSecret
+// Optional parameters: Label; ContentType; Tags; IsSecret; IfMatch;
+// IfNoneMatch
 func (client *ClientImpl) CreateOrUpdateKeyValue(args CreateOrUpdateKeyValueArgs) (KeyValue, error) {
-	result := KeyValue{}
+	return client.CreateOrUpdateKeyValueWithContext(context.Background(), args)
+}
+
+// CreateOrUpdateKeyValueWithContext is CreateOrUpdateKeyValue sending the
+// request in ctx.
+func (client *ClientImpl) CreateOrUpdateKeyValueWithContext(ctx context.Context, args CreateOrUpdateKeyValueArgs) (result KeyValue, err error) {
+	ctx, op := client.startOperation(ctx, "appconfig.CreateOrUpdateKeyValue", AttributeKey, args.Key, AttributeLabel, args.Label)
+	defer func() { op.end(err) }()
 
 	if args.IsSecret {
 		args.Value = fmt.Sprintf("{\"uri\":\"%s\"}", args.Value)
@@ -150,13 +444,19 @@ func (client *ClientImpl) CreateOrUpdateKeyValue(args CreateOrUpdateKeyValueArgs
 		}
 	}
 
-	req, err := client.createRequest(
-		args.Label,
-		args.Key,
+	decorators := []autorest.PrepareDecorator{
 		autorest.AsContentType(defaultContentType),
 		autorest.AsPut(),
 		autorest.WithJSON(args),
-	)
+	}
+	if args.IfMatch != "" {
+		decorators = append(decorators, autorest.WithHeader("If-Match", quoteETag(args.IfMatch)))
+	}
+	if args.IfNoneMatch != "" {
+		decorators = append(decorators, autorest.WithHeader("If-None-Match", quoteETag(args.IfNoneMatch)))
+	}
+
+	req, err := client.createRequest(ctx, args.Label, args.Key, decorators...)
 	if err != nil {
 		return result, err
 	}
@@ -175,70 +475,187 @@ func (client *ClientImpl) CreateOrUpdateKeyValue(args CreateOrUpdateKeyValueArgs
 
 // DeleteKeyValue deletes an App Configuration Key-Value.
 func (client *ClientImpl) DeleteKeyValue(key, label string) error {
-	req, err := client.createRequest(label, url.QueryEscape(key), autorest.AsDelete())
+	return client.DeleteKeyValueWithContext(context.Background(), key, label)
+}
+
+// DeleteKeyValueWithContext is DeleteKeyValue sending the request in ctx.
+func (client *ClientImpl) DeleteKeyValueWithContext(ctx context.Context, key, label string) error {
+	return client.DeleteKeyValueWithArgs(ctx, DeleteKeyValueArgs{Key: key, Label: label})
+}
+
+// DeleteKeyValueWithArgs deletes an App Configuration Key-Value.
+//
+// Required parameters: Key
+//
+// Optional parameters: Label; IfMatch
+func (client *ClientImpl) DeleteKeyValueWithArgs(ctx context.Context, args DeleteKeyValueArgs) (err error) {
+	ctx, op := client.startOperation(ctx, "appconfig.DeleteKeyValue", AttributeKey, args.Key, AttributeLabel, args.Label)
+	defer func() { op.end(err) }()
+
+	decorators := []autorest.PrepareDecorator{autorest.AsDelete()}
+	if args.IfMatch != "" {
+		decorators = append(decorators, autorest.WithHeader("If-Match", quoteETag(args.IfMatch)))
+	}
+
+	req, err := client.createRequest(ctx, args.Label, url.QueryEscape(args.Key), decorators...)
+	if err != nil {
+		return err
+	}
+	response, err := client.sendRequest(req)
 	if err != nil {
 		return err
 	}
-	_, err = client.sendRequest(req)
-	return err
+	response.Body.Close()
+	return nil
+}
+
+// LockKeyValue locks an App Configuration Key-Value, making it
+// read-only, and returns the updated Key-Value.
+func (client *ClientImpl) LockKeyValue(ctx context.Context, key, label string) (result KeyValue, err error) {
+	ctx, op := client.startOperation(ctx, "appconfig.LockKeyValue", AttributeKey, key, AttributeLabel, label)
+	defer func() { op.end(err) }()
+
+	return client.setLock(ctx, key, label, autorest.AsPut())
+}
+
+// UnlockKeyValue unlocks an App Configuration Key-Value and returns the
+// updated Key-Value.
+func (client *ClientImpl) UnlockKeyValue(ctx context.Context, key, label string) (result KeyValue, err error) {
+	ctx, op := client.startOperation(ctx, "appconfig.UnlockKeyValue", AttributeKey, key, AttributeLabel, label)
+	defer func() { op.end(err) }()
+
+	return client.setLock(ctx, key, label, autorest.AsDelete())
+}
+
+func (client *ClientImpl) setLock(ctx context.Context, key, label string, method autorest.PrepareDecorator) (KeyValue, error) {
+	result := KeyValue{}
+
+	req, err := client.createPathRequest(ctx, "/locks/{key}", label, url.QueryEscape(key), method)
+	if err != nil {
+		return result, err
+	}
+
+	response, err := client.sendRequest(req)
+	if err != nil {
+		return result, err
+	}
+
+	if err = getJSON(response, &result); err != nil {
+		return result, err
+	}
+
+	return result, nil
 }
 
-func (client *ClientImpl) createRequest(label, key string, additionalDecorator ...autorest.PrepareDecorator) (*http.Request, error) {
+func (client *ClientImpl) createRequest(ctx context.Context, label, key string, additionalDecorator ...autorest.PrepareDecorator) (*http.Request, error) {
+	return client.createPathRequest(ctx, "/kv/{key}", label, key, additionalDecorator...)
+}
+
+func (client *ClientImpl) createPathRequest(ctx context.Context, path, label, key string, additionalDecorator ...autorest.PrepareDecorator) (*http.Request, error) {
 	queryParameters := map[string]interface{}{
-		"label":       label,
+		"label":       autorest.Encode("query", label),
 		"api-version": apiVersion,
 	}
 
 	req, err := client.preparer(
+		path,
 		label,
 		key,
 		queryParameters,
 		additionalDecorator...,
-	).Prepare(&http.Request{})
+	).Prepare((&http.Request{}).WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
 	return req, nil
 }
 
-func (client *ClientImpl) createListRequest(label, key string, additionalDecorator ...autorest.PrepareDecorator) (*http.Request, error) {
+func (client *ClientImpl) createListRequest(ctx context.Context, path, label, key string, additionalDecorator ...autorest.PrepareDecorator) (*http.Request, error) {
 	queryParameters := map[string]interface{}{
-		"label":       label,
+		"label":       autorest.Encode("query", label),
 		"api-version": apiVersion,
 	}
 
 	req, err := client.listPreparer(
+		path,
 		label,
 		key,
 		queryParameters,
 		additionalDecorator...,
-	).Prepare(&http.Request{})
+	).Prepare((&http.Request{}).WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
 	return req, nil
 }
 
+func (client *ClientImpl) createQueryRequest(ctx context.Context, path string, query map[string]interface{}, additionalDecorator ...autorest.PrepareDecorator) (*http.Request, error) {
+	decorators := []autorest.PrepareDecorator{
+		autorest.WithBaseURL(fmt.Sprintf("%s%s", client.Endpoint, path)),
+		autorest.WithQueryParameters(query),
+		client.Client.WithAuthorization(),
+	}
+	decorators = append(decorators, additionalDecorator...)
+
+	return autorest.CreatePreparer(decorators...).Prepare((&http.Request{}).WithContext(ctx))
+}
+
+func (client *ClientImpl) createNextRequest(ctx context.Context, nextLink string, additionalDecorator ...autorest.PrepareDecorator) (*http.Request, error) {
+	base, err := url.Parse(client.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	link, err := url.Parse(nextLink)
+	if err != nil {
+		return nil, err
+	}
+
+	decorators := []autorest.PrepareDecorator{
+		autorest.WithBaseURL(base.ResolveReference(link).String()),
+		client.Client.WithAuthorization(),
+		autorest.AsGet(),
+	}
+	decorators = append(decorators, additionalDecorator...)
+
+	return autorest.CreatePreparer(decorators...).Prepare((&http.Request{}).WithContext(ctx))
+}
+
 func (client *ClientImpl) sendRequest(req *http.Request) (*http.Response, error) {
-	resp, err := client.Send(req)
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	if op, ok := operationFrom(req.Context()); ok {
+		op.attempts = 0
+	}
+	if client.APIVersion != "" {
+		var err error
+		req, err = autorest.Prepare(req, autorest.WithQueryParameters(map[string]interface{}{
+			"api-version": client.APIVersion,
+		}))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := client.pipeline().send(req, func(req *http.Request) (*http.Response, error) {
+		return client.Send(req)
+	})
 	if err != nil {
 		return nil, err
 	}
 	if resp.StatusCode >= 400 {
-		s, _ := ioutil.ReadAll(resp.Body)
-		body := string(s)
-		return resp, fmt.Errorf("ERROR: %s - Response Body: %s", resp.Status, body)
+		return resp, newResponseError(resp)
 	}
 	return resp, err
 }
 
-func (client *ClientImpl) preparer(label, key string, query map[string]interface{}, additionalDecorators ...autorest.PrepareDecorator) autorest.Preparer {
+func (client *ClientImpl) preparer(path, label, key string, query map[string]interface{}, additionalDecorators ...autorest.PrepareDecorator) autorest.Preparer {
 	pathParameters := map[string]interface{}{
 		"key": key,
 	}
 	decorators := []autorest.PrepareDecorator{
 		autorest.WithBaseURL(client.Endpoint),
-		autorest.WithPathParameters("/kv/{key}", pathParameters),
+		autorest.WithPathParameters(path, pathParameters),
 		autorest.WithQueryParameters(query),
 		client.Client.WithAuthorization(),
 	}
@@ -247,10 +664,10 @@ func (client *ClientImpl) preparer(label, key string, query map[string]interface
 	return autorest.CreatePreparer(decorators...)
 }
 
-func (client *ClientImpl) listPreparer(label string, key string, query map[string]interface{}, additionalDecorators ...autorest.PrepareDecorator) autorest.Preparer {
-	query["key"] = key
+func (client *ClientImpl) listPreparer(path, label, key string, query map[string]interface{}, additionalDecorators ...autorest.PrepareDecorator) autorest.Preparer {
+	query["key"] = autorest.Encode("query", key)
 	decorators := []autorest.PrepareDecorator{
-		autorest.WithBaseURL(fmt.Sprintf("%s/kv", client.Endpoint)),
+		autorest.WithBaseURL(fmt.Sprintf("%s%s", client.Endpoint, path)),
 		autorest.WithQueryParameters(query),
 		client.Client.WithAuthorization(),
 	}
@@ -259,6 +676,140 @@ func (client *ClientImpl) listPreparer(label string, key string, query map[strin
 	return autorest.CreatePreparer(decorators...)
 }
 
+// getNextLink extracts the continuation link from a response Link header,
+// e.g. `</kv?after=abc&api-version=1.0>; rel="next"`. It returns an empty
+// string if there are no more pages.
+func getNextLink(response *http.Response) string {
+	for _, header := range response.Header.Values("Link") {
+		for header != "" {
+			start := strings.Index(header, "<")
+			end := strings.Index(header, ">")
+			if start < 0 || end < start {
+				break
+			}
+			target := header[start+1 : end]
+
+			var params []string
+			params, header = splitLinkParams(header[end+1:])
+			if hasLinkRel(params, "next") {
+				return target
+			}
+		}
+	}
+	return ""
+}
+
+// splitLinkParams splits the parameters of a link, up to the comma
+// separating it from the next link, and returns the remaining links.
+func splitLinkParams(s string) ([]string, string) {
+	var params []string
+	quoted := false
+	last := 0
+	for i, c := range s {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == ';' && !quoted:
+			params = append(params, s[last:i])
+			last = i + 1
+		case c == ',' && !quoted:
+			return append(params, s[last:i]), s[i+1:]
+		}
+	}
+	return append(params, s[last:]), ""
+}
+
+// hasLinkRel reports whether the rel parameter, a space separated list of
+// relation types, contains rel.
+func hasLinkRel(params []string, rel string) bool {
+	for _, param := range params {
+		i := strings.Index(param, "=")
+		if i < 0 || !strings.EqualFold(strings.TrimSpace(param[:i]), "rel") {
+			continue
+		}
+		for _, token := range strings.Fields(strings.Trim(strings.TrimSpace(param[i+1:]), `"`)) {
+			if strings.EqualFold(token, rel) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withSelect returns the decorators that restrict the returned KeyValue
+// fields to the selected ones. All fields are returned if none is selected.
+func withSelect(fields []Field) []autorest.PrepareDecorator {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = string(field)
+	}
+	return []autorest.PrepareDecorator{
+		autorest.WithQueryParameters(map[string]interface{}{
+			"$select": strings.Join(names, ","),
+		}),
+	}
+}
+
+// withAcceptDatetime returns the decorators that request the KeyValues as
+// they were at the asOf time. The current state is requested if it is zero.
+func withAcceptDatetime(asOf time.Time) []autorest.PrepareDecorator {
+	if asOf.IsZero() {
+		return nil
+	}
+	return []autorest.PrepareDecorator{
+		autorest.WithHeader("Accept-Datetime", asOf.UTC().Format(http.TimeFormat)),
+	}
+}
+
+// quoteETag formats an ETag to be sent in the If-Match and If-None-Match
+// headers. The wildcard "*" and already quoted ETags are kept as is.
+func quoteETag(etag string) string {
+	if etag == "*" || strings.HasPrefix(etag, "\"") {
+		return etag
+	}
+	return fmt.Sprintf("%q", etag)
+}
+
+// collectPages sends the request and every following page request,
+// passing each response to decode.
+func (client *ClientImpl) collectPages(ctx context.Context, req *http.Request, decode func(*http.Response) error) error {
+	for {
+		response, err := client.sendRequest(req)
+		if err != nil {
+			return err
+		}
+		if err = decode(response); err != nil {
+			return err
+		}
+		client.recordPage(ctx)
+
+		nextLink := getNextLink(response)
+		if nextLink == "" {
+			return nil
+		}
+		req, err = client.createNextRequest(ctx, nextLink)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// collectKeyValues fetches every page of the pager into a single KeyValues.
+func collectKeyValues(ctx context.Context, pager KeyValuesPager) (KeyValues, error) {
+	var result KeyValues
+	for pager.Next(ctx) {
+		result.Items = append(result.Items, pager.Page().Items...)
+	}
+	if err := pager.Err(); err != nil {
+		return KeyValues{}, err
+	}
+	return result, nil
+}
+
 func getJSON(response *http.Response, target interface{}) error {
 	defer response.Body.Close()
 	return json.NewDecoder(response.Body).Decode(target)
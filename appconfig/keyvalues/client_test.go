@@ -1,13 +1,19 @@
 package keyvalues
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/Azure/go-autorest/autorest"
 	"github.com/google/go-cmp/cmp"
 
 	"github.com/stone-payments/appconfig-go-sdk/appconfig/test"
@@ -18,8 +24,11 @@ var (
 	fakeKey            = "fakeKey"
 	fakeLabel          = "fakeLabel"
 	fakeValue          = "fakeValue"
+	fakeEtag           = "fakeEtag"
 	fakeSecretResponse = "{\"uri\":\"fakeValue\"}"
-	errString          = "ERROR: 500 Internal Server Error - Response Body: "
+	errInternal        = &ResponseError{StatusCode: 500, Status: "500 Internal Server Error"}
+	errPrecondition    = &ResponseError{StatusCode: 412, Status: "412 Precondition Failed"}
+	fakeAsOf           = time.Date(2021, time.August, 2, 15, 4, 5, 0, time.FixedZone("BRT", -3*60*60))
 )
 
 type token struct {
@@ -86,6 +95,74 @@ func TestNewClientAzureAD(t *testing.T) {
 	}
 }
 
+func TestNewClientAzureADCloud(t *testing.T) {
+	type args struct {
+		audience string
+	}
+	type want struct {
+		resource string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"CustomAudience": {
+			reason: "Should request tokens for the Cloud audience",
+			args: args{
+				audience: AzurePublicCloud.Audience,
+			},
+			want: want{
+				resource: "https://azconfig.io",
+			},
+		},
+		"EndpointAudience": {
+			reason: "Should request tokens for the endpoint if the Cloud has no audience",
+			args: args{
+				audience: "",
+			},
+			want: want{
+				resource: "endpoint",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var resource string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.String(), "oauth") {
+					_ = r.ParseForm()
+					resource = r.PostForm.Get("resource")
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.Contains(r.URL.String(), "kv") {
+					_ = json.NewEncoder(w).Encode(KeyValue{})
+				}
+			}))
+			defer server.Close()
+
+			if tc.want.resource == "endpoint" {
+				tc.want.resource = server.URL
+			}
+			cloud := Cloud{ActiveDirectoryEndpoint: server.URL, Audience: tc.args.audience}
+			args := NewClientAzureADArgs{ClientID: fakeIDs, TenantID: fakeIDs, ClientSecret: fakeIDs, ResourceEndpoint: server.URL, Cloud: cloud}
+			c, err := NewClientAzureAD(args)
+			if err != nil {
+				t.Fatalf("NewClientAzureAD(...): unexpected error: %v", err)
+			}
+
+			if _, err := c.GetKeyValue(fakeKey, fakeLabel); err != nil {
+				t.Fatalf("GetKeyValue(...): unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want.resource, resource); diff != "" {
+				t.Errorf("%s: -want resource, +got resource:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestNewClientCli(t *testing.T) {
 	type args struct {
 		endpoint string
@@ -121,6 +198,135 @@ func TestNewClientCli(t *testing.T) {
 	}
 }
 
+func TestNewClientManagedIdentity(t *testing.T) {
+	type args struct {
+		NewClientManagedIdentityArgs
+	}
+	type want struct {
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"CreateNewClientSuccess": {
+			reason: "Should create a new Client sucessfully",
+			args: args{
+				NewClientManagedIdentityArgs: NewClientManagedIdentityArgs{
+					ClientID:         "fakeID",
+					ResourceEndpoint: "https://fake.azconfig.io",
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"CreateNewClientCloudAudience": {
+			reason: "Should request tokens for the Cloud audience",
+			args: args{
+				NewClientManagedIdentityArgs: NewClientManagedIdentityArgs{
+					Cloud: AzureChinaCloud,
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"CreateNewClientError": {
+			reason: "Should return an error if the Client creation fails",
+			args: args{
+				NewClientManagedIdentityArgs: NewClientManagedIdentityArgs{},
+			},
+			want: want{
+				err: errors.New("failed to get oauth token from MSI: parameter 'resource' cannot be empty"),
+			},
+		},
+	}
+
+	// Skips the IMDS availability probe, which depends on the environment.
+	os.Setenv("MSI_ENDPOINT", "http://localhost/msi/token")
+	defer os.Unsetenv("MSI_ENDPOINT")
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewClientManagedIdentity(tc.args.NewClientManagedIdentityArgs)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("NewClientManagedIdentity(...): -want error, +got error:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewClientWorkloadIdentity(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := ioutil.WriteFile(tokenFile, []byte("fakeAssertion\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	type args struct {
+		tokenFilePath string
+	}
+	type want struct {
+		kv  KeyValue
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"FederatedTokenExchanged": {
+			reason: "Should send the federated token as client assertion",
+			args: args{
+				tokenFilePath: tokenFile,
+			},
+			want: want{
+				kv:  KeyValue{Key: &fakeKey},
+				err: nil,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.String(), "oauth") {
+					_ = r.ParseForm()
+					if r.PostForm.Get("client_assertion") != "fakeAssertion" || r.PostForm.Get("client_assertion_type") != clientAssertionType {
+						w.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.Contains(r.URL.String(), "kv") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(KeyValue{Key: &fakeKey})
+				}
+			}))
+			defer server.Close()
+
+			args := NewClientWorkloadIdentityArgs{ClientID: fakeIDs, TenantID: fakeIDs, TokenFilePath: tc.args.tokenFilePath, ResourceEndpoint: server.URL, AADEndpoint: server.URL}
+			c, err := NewClientWorkloadIdentity(args)
+			if err != nil {
+				t.Fatalf("NewClientWorkloadIdentity(...): unexpected error: %v", err)
+			}
+
+			got, err := c.GetKeyValue(fakeKey, fakeLabel)
+
+			if diff := cmp.Diff(tc.want.kv, got); diff != "" {
+				t.Errorf("GetKeyValue(...): -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("GetKeyValue(...): -want error, +got error:\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestListKeyValues(t *testing.T) {
 	type args struct {
 		ListKeyValuesArgs
@@ -150,15 +356,328 @@ func TestListKeyValues(t *testing.T) {
 				}
 			}),
 			args: args{
-				ListKeyValuesArgs: ListKeyValuesArgs{},
+				ListKeyValuesArgs: ListKeyValuesArgs{},
+			},
+			want: want{
+				kvs: KeyValues{Items: []KeyValue{{}, {}}},
+				err: nil,
+			},
+		},
+		"ListKeyValuesFollowsNextLink": {
+			reason: "Should follow the Link header until all pages are fetched",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.Contains(r.URL.String(), "kv") {
+					response := KeyValues{Items: []KeyValue{{Key: &fakeKey}}}
+					if r.URL.Query().Get("after") == "" {
+						w.Header().Set("Link", `</kv?after=fake&api-version=1.0>; rel="next"`)
+						response = KeyValues{Items: []KeyValue{{}, {}}}
+					}
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&response)
+				}
+			}),
+			args: args{
+				ListKeyValuesArgs: ListKeyValuesArgs{},
+			},
+			want: want{
+				kvs: KeyValues{Items: []KeyValue{{}, {}, {Key: &fakeKey}}},
+				err: nil,
+			},
+		},
+		"ListKeyValuesAsOf": {
+			reason: "Should send the AsOf time as the Accept-Datetime header",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.Contains(r.URL.String(), "kv") {
+					if r.Header.Get("Accept-Datetime") != "Mon, 02 Aug 2021 18:04:05 GMT" {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(KeyValues{Items: []KeyValue{{Key: &fakeKey}}})
+				}
+			}),
+			args: args{
+				ListKeyValuesArgs: ListKeyValuesArgs{AsOf: fakeAsOf},
+			},
+			want: want{
+				kvs: KeyValues{Items: []KeyValue{{Key: &fakeKey}}},
+				err: nil,
+			},
+		},
+		"ListKeyValuesSelect": {
+			reason: "Should only request the selected fields",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.Contains(r.URL.String(), "kv") {
+					if r.URL.Query().Get("$select") != "key,label,etag" {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(KeyValues{Items: []KeyValue{{Key: &fakeKey, Label: &fakeLabel, Etag: &fakeEtag}}})
+				}
+			}),
+			args: args{
+				ListKeyValuesArgs: ListKeyValuesArgs{Select: []Field{FieldKey, FieldLabel, FieldEtag}},
+			},
+			want: want{
+				kvs: KeyValues{Items: []KeyValue{{Key: &fakeKey, Label: &fakeLabel, Etag: &fakeEtag}}},
+				err: nil,
+			},
+		},
+		"ListKeyValuesMultipleFilters": {
+			reason: "Should join and escape the Keys and Labels filters",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.Contains(r.URL.String(), "kv") {
+					if r.URL.Query().Get("key") != `app:*,a\,b` || r.URL.Query().Get("label") != `prod,\0` {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(KeyValues{Items: []KeyValue{{Key: &fakeKey}}})
+				}
+			}),
+			args: args{
+				ListKeyValuesArgs: ListKeyValuesArgs{
+					Keys:   []string{"app:*", "a,b"},
+					Labels: []string{"prod", NullLabel},
+				},
+			},
+			want: want{
+				kvs: KeyValues{Items: []KeyValue{{Key: &fakeKey}}},
+				err: nil,
+			},
+		},
+		"ListKeyValuesEncodedFilters": {
+			reason: "Should send the Keys and Labels filters with their plus and percent signs",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.Contains(r.URL.String(), "kv") {
+					if r.URL.Query().Get("key") != "c++" || r.URL.Query().Get("label") != "a+b,100%" {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(KeyValues{Items: []KeyValue{{Key: &fakeKey}}})
+				}
+			}),
+			args: args{
+				ListKeyValuesArgs: ListKeyValuesArgs{
+					Keys:   []string{"c++"},
+					Labels: []string{"a+b", "100%"},
+				},
+			},
+			want: want{
+				kvs: KeyValues{Items: []KeyValue{{Key: &fakeKey}}},
+				err: nil,
+			},
+		},
+		"ListKeyValuesTags": {
+			reason: "Should send an escaped tags filter for each tag on every page",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.Contains(r.URL.String(), "kv") {
+					if diff := cmp.Diff([]string{"env=a\\=b", "owner=a+b", "team=100%"}, r.URL.Query()["tags"]); diff != "" ||
+						r.URL.Query().Get("api-version") != latestAPIVersion {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					response := KeyValues{Items: []KeyValue{{Key: &fakeKey}}}
+					if r.URL.Query().Get("after") == "" {
+						query := r.URL.Query()
+						query.Set("after", "fake")
+						w.Header().Set("Link", "</kv?"+query.Encode()+`>; rel="next"`)
+						response = KeyValues{Items: []KeyValue{{}}}
+					}
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&response)
+				}
+			}),
+			args: args{
+				ListKeyValuesArgs: ListKeyValuesArgs{
+					Tags: map[string]string{"owner": "a+b", "env": "a=b", "team": "100%"},
+				},
+			},
+			want: want{
+				kvs: KeyValues{Items: []KeyValue{{}, {Key: &fakeKey}}},
+				err: nil,
+			},
+		},
+		"ListKeyValuesSnapshot": {
+			reason: "Should list the KeyValues of the Snapshot without key and label filters",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.Contains(r.URL.String(), "kv") {
+					query := r.URL.Query()
+					if query.Get("snapshot") != "fakeSnapshot" || query.Get("key") != "" || query.Get("label") != "" {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(KeyValues{Items: []KeyValue{{Key: &fakeKey}}})
+				}
+			}),
+			args: args{
+				ListKeyValuesArgs: ListKeyValuesArgs{Snapshot: "fakeSnapshot"},
+			},
+			want: want{
+				kvs: KeyValues{Items: []KeyValue{{Key: &fakeKey}}},
+				err: nil,
+			},
+		},
+		"ListKeyValuesEncodedSnapshot": {
+			reason: "Should send the Snapshot name with its plus and percent signs",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.Contains(r.URL.String(), "kv") {
+					if r.URL.Query().Get("snapshot") != "a+b 100%" {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(KeyValues{Items: []KeyValue{{Key: &fakeKey}}})
+				}
+			}),
+			args: args{
+				ListKeyValuesArgs: ListKeyValuesArgs{Snapshot: "a+b 100%"},
+			},
+			want: want{
+				kvs: KeyValues{Items: []KeyValue{{Key: &fakeKey}}},
+				err: nil,
+			},
+		},
+		"ListKeyValuesSnapshotFilters": {
+			reason: "Should fail without request if the Snapshot is combined with filters",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				w.WriteHeader(http.StatusBadRequest)
+			}),
+			args: args{
+				ListKeyValuesArgs: ListKeyValuesArgs{Snapshot: "fakeSnapshot", Keys: []string{"app:*"}},
+			},
+			want: want{
+				kvs: KeyValues{},
+				err: ErrSnapshotFilters,
+			},
+		},
+		"ListKeyValuesInternalError": {
+			reason: "Should return an error if the list returns Status Code greater than 399",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.Contains(r.URL.String(), "kv") {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}),
+			args: args{
+				ListKeyValuesArgs: ListKeyValuesArgs{},
+			},
+			want: want{
+				kvs: KeyValues{},
+				err: errInternal,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+			args := NewClientAzureADArgs{ClientID: fakeIDs, TenantID: fakeIDs, ClientSecret: fakeIDs, ResourceEndpoint: server.URL, AADEndpoint: server.URL}
+			c, _ := NewClientAzureAD(args)
+
+			got, err := c.ListKeyValues(tc.args.ListKeyValuesArgs)
+
+			if diff := cmp.Diff(tc.want.kvs, got); diff != "" {
+				t.Errorf("ListKeyValues(...): -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("ListKeyValues(...): -want error, +got error:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetKeyValue(t *testing.T) {
+	type args struct {
+		key   string
+		label string
+	}
+	type want struct {
+		kv  KeyValue
+		err error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler http.Handler
+		args    args
+		want    want
+	}{
+		"GetKeyValueSucessfully": {
+			reason: "Should return a KeyValue",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				w.WriteHeader(http.StatusOK)
+				if strings.Contains(r.URL.String(), "oauth") {
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.Contains(r.URL.String(), "kv") {
+					_ = json.NewEncoder(w).Encode(KeyValue{Key: &fakeKey, Label: &fakeLabel})
+				}
+			}),
+			args: args{
+				key:   fakeKey,
+				label: fakeLabel,
 			},
 			want: want{
-				kvs: KeyValues{Items: []KeyValue{{}, {}}},
+				kv: KeyValue{
+					Key:   &fakeKey,
+					Label: &fakeLabel,
+				},
 				err: nil,
 			},
 		},
-		"ListKeyValuesInternalError": {
-			reason: "Should return an error if the list returns Status Code greater than 399",
+		"GetKeyValueInternalError": {
+			reason: "Should return an error if the GET returns Status Code greater than 399",
 			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				r.Body.Close()
 				if strings.Contains(r.URL.String(), "oauth") {
@@ -170,11 +689,12 @@ func TestListKeyValues(t *testing.T) {
 				}
 			}),
 			args: args{
-				ListKeyValuesArgs: ListKeyValuesArgs{},
+				key:   fakeKey,
+				label: fakeLabel,
 			},
 			want: want{
-				kvs: KeyValues{},
-				err: errors.New(errString),
+				kv:  KeyValue{},
+				err: errInternal,
 			},
 		},
 	}
@@ -186,60 +706,87 @@ func TestListKeyValues(t *testing.T) {
 			args := NewClientAzureADArgs{ClientID: fakeIDs, TenantID: fakeIDs, ClientSecret: fakeIDs, ResourceEndpoint: server.URL, AADEndpoint: server.URL}
 			c, _ := NewClientAzureAD(args)
 
-			got, err := c.ListKeyValues(tc.args.ListKeyValuesArgs)
+			got, err := c.GetKeyValue(tc.args.key, tc.args.label)
 
-			if diff := cmp.Diff(tc.want.kvs, got); diff != "" {
-				t.Errorf("ListKeyValues(...): -want, +got:\n%s", diff)
+			if diff := cmp.Diff(tc.want.kv, got); diff != "" {
+				t.Errorf("GetKeyValue(...): -want, +got:\n%s", diff)
 			}
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
-				t.Errorf("ListKeyValues(...): -want error, +got error:\n%s", diff)
+				t.Errorf("GetKeyValue(...): -want error, +got error:\n%s", diff)
 			}
 		})
 	}
 }
 
-func TestGetKeyValue(t *testing.T) {
+func TestGetKeyValueWithArgs(t *testing.T) {
 	type args struct {
-		key   string
-		label string
+		GetKeyValueArgs
 	}
 	type want struct {
 		kv  KeyValue
 		err error
 	}
 
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body.Close()
+		if strings.Contains(r.URL.String(), "oauth") {
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(&token{})
+		}
+		if strings.Contains(r.URL.String(), "kv") {
+			if r.Header.Get("If-None-Match") == `"fakeEtag"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(KeyValue{Key: &fakeKey, Etag: &fakeEtag})
+		}
+	})
+
 	cases := map[string]struct {
 		reason  string
 		handler http.Handler
 		args    args
 		want    want
 	}{
-		"GetKeyValueSucessfully": {
-			reason: "Should return a KeyValue",
+		"GetKeyValueModified": {
+			reason:  "Should return the KeyValue if the Etag does not match",
+			handler: handler,
+			args: args{
+				GetKeyValueArgs: GetKeyValueArgs{Key: fakeKey, IfNoneMatch: "otherEtag"},
+			},
+			want: want{
+				kv:  KeyValue{Key: &fakeKey, Etag: &fakeEtag},
+				err: nil,
+			},
+		},
+		"GetKeyValueAsOf": {
+			reason: "Should send the AsOf time as the Accept-Datetime header",
 			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				r.Body.Close()
-				w.WriteHeader(http.StatusOK)
 				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
 					_ = json.NewEncoder(w).Encode(&token{})
 				}
 				if strings.Contains(r.URL.String(), "kv") {
-					_ = json.NewEncoder(w).Encode(KeyValue{Key: &fakeKey, Label: &fakeLabel})
+					if r.Header.Get("Accept-Datetime") != "Mon, 02 Aug 2021 18:04:05 GMT" {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(KeyValue{Key: &fakeKey})
 				}
 			}),
 			args: args{
-				key:   fakeKey,
-				label: fakeLabel,
+				GetKeyValueArgs: GetKeyValueArgs{Key: fakeKey, AsOf: fakeAsOf},
 			},
 			want: want{
-				kv: KeyValue{
-					Key:   &fakeKey,
-					Label: &fakeLabel,
-				},
+				kv:  KeyValue{Key: &fakeKey},
 				err: nil,
 			},
 		},
-		"GetKeyValueInternalError": {
-			reason: "Should return an error if the GET returns Status Code greater than 399",
+		"GetKeyValueSelect": {
+			reason: "Should only request the selected fields",
 			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				r.Body.Close()
 				if strings.Contains(r.URL.String(), "oauth") {
@@ -247,16 +794,31 @@ func TestGetKeyValue(t *testing.T) {
 					_ = json.NewEncoder(w).Encode(&token{})
 				}
 				if strings.Contains(r.URL.String(), "kv") {
-					w.WriteHeader(http.StatusInternalServerError)
+					if r.URL.Query().Get("$select") != "etag" {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(KeyValue{Etag: &fakeEtag})
 				}
 			}),
 			args: args{
-				key:   fakeKey,
-				label: fakeLabel,
+				GetKeyValueArgs: GetKeyValueArgs{Key: fakeKey, Select: []Field{FieldEtag}},
+			},
+			want: want{
+				kv:  KeyValue{Etag: &fakeEtag},
+				err: nil,
+			},
+		},
+		"GetKeyValueNotModified": {
+			reason:  "Should return ErrNotModified if the Etag matches",
+			handler: handler,
+			args: args{
+				GetKeyValueArgs: GetKeyValueArgs{Key: fakeKey, IfNoneMatch: fakeEtag},
 			},
 			want: want{
 				kv:  KeyValue{},
-				err: errors.New(errString),
+				err: ErrNotModified,
 			},
 		},
 	}
@@ -268,13 +830,13 @@ func TestGetKeyValue(t *testing.T) {
 			args := NewClientAzureADArgs{ClientID: fakeIDs, TenantID: fakeIDs, ClientSecret: fakeIDs, ResourceEndpoint: server.URL, AADEndpoint: server.URL}
 			c, _ := NewClientAzureAD(args)
 
-			got, err := c.GetKeyValue(tc.args.key, tc.args.label)
+			got, err := c.GetKeyValueWithArgs(context.Background(), tc.args.GetKeyValueArgs)
 
 			if diff := cmp.Diff(tc.want.kv, got); diff != "" {
-				t.Errorf("GetKeyValue(...): -want, +got:\n%s", diff)
+				t.Errorf("GetKeyValueWithArgs(...): -want, +got:\n%s", diff)
 			}
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
-				t.Errorf("GetKeyValue(...): -want error, +got error:\n%s", diff)
+				t.Errorf("GetKeyValueWithArgs(...): -want error, +got error:\n%s", diff)
 			}
 		})
 	}
@@ -352,6 +914,64 @@ func TestCreateOrUpdateKeyValue(t *testing.T) {
 				err: nil,
 			},
 		},
+		"CreateOrUpdateKeyValueIfMatch": {
+			reason: "Should send the quoted Etag in the If-Match header",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.Contains(r.URL.String(), "kv") {
+					if r.Header.Get("If-Match") != `"fakeEtag"` {
+						w.WriteHeader(http.StatusPreconditionFailed)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(KeyValue{Key: &fakeKey, Etag: &fakeEtag})
+				}
+			}),
+			args: args{
+				CreateOrUpdateKeyValueArgs: CreateOrUpdateKeyValueArgs{
+					Key:     fakeKey,
+					IfMatch: fakeEtag,
+				},
+			},
+			want: want{
+				kv: KeyValue{
+					Key:  &fakeKey,
+					Etag: &fakeEtag,
+				},
+				err: nil,
+			},
+		},
+		"CreateOrUpdateKeyValuePreconditionFailed": {
+			reason: "Should return a precondition error if the KeyValue already exists",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.Contains(r.URL.String(), "kv") {
+					if r.Header.Get("If-None-Match") == "*" {
+						w.WriteHeader(http.StatusPreconditionFailed)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+				}
+			}),
+			args: args{
+				CreateOrUpdateKeyValueArgs: CreateOrUpdateKeyValueArgs{
+					Key:         fakeKey,
+					IfNoneMatch: "*",
+				},
+			},
+			want: want{
+				kv:  KeyValue{},
+				err: errPrecondition,
+			},
+		},
 		"CreateOrUpdateKeyValueInternalError": {
 			reason: "Should return an error if the request returns Status Code greater than 399",
 			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -372,7 +992,7 @@ func TestCreateOrUpdateKeyValue(t *testing.T) {
 			},
 			want: want{
 				kv:  KeyValue{},
-				err: errors.New(errString),
+				err: errInternal,
 			},
 		},
 	}
@@ -448,7 +1068,7 @@ func TestDeleteKeyValue(t *testing.T) {
 				label: fakeLabel,
 			},
 			want: want{
-				err: errors.New(errString),
+				err: errInternal,
 			},
 		},
 	}
@@ -468,3 +1088,296 @@ func TestDeleteKeyValue(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteKeyValueWithArgs(t *testing.T) {
+	type args struct {
+		DeleteKeyValueArgs
+	}
+	type want struct {
+		err error
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body.Close()
+		if strings.Contains(r.URL.String(), "oauth") {
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(&token{})
+		}
+		if strings.Contains(r.URL.String(), "kv") {
+			if r.Header.Get("If-Match") != `"fakeEtag"` {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	cases := map[string]struct {
+		reason  string
+		handler http.Handler
+		args    args
+		want    want
+	}{
+		"DeleteKeyValueIfMatch": {
+			reason:  "Should delete the KeyValue if the Etag matches",
+			handler: handler,
+			args: args{
+				DeleteKeyValueArgs: DeleteKeyValueArgs{Key: fakeKey, Label: fakeLabel, IfMatch: fakeEtag},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"DeleteKeyValuePreconditionFailed": {
+			reason:  "Should return a precondition error if the Etag does not match",
+			handler: handler,
+			args: args{
+				DeleteKeyValueArgs: DeleteKeyValueArgs{Key: fakeKey, Label: fakeLabel, IfMatch: "otherEtag"},
+			},
+			want: want{
+				err: errPrecondition,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+			args := NewClientAzureADArgs{ClientID: fakeIDs, TenantID: fakeIDs, ClientSecret: fakeIDs, ResourceEndpoint: server.URL, AADEndpoint: server.URL}
+			c, _ := NewClientAzureAD(args)
+
+			err := c.DeleteKeyValueWithArgs(context.Background(), tc.args.DeleteKeyValueArgs)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("DeleteKeyValueWithArgs(...): -want error, +got error:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLockKeyValue(t *testing.T) {
+	type args struct {
+		key    string
+		label  string
+		unlock bool
+	}
+	type want struct {
+		kv  KeyValue
+		err error
+	}
+
+	locked := true
+	unlocked := false
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body.Close()
+		if strings.Contains(r.URL.String(), "oauth") {
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(&token{})
+		}
+		if strings.Contains(r.URL.String(), "locks") {
+			w.WriteHeader(http.StatusOK)
+			response := KeyValue{Key: &fakeKey, Label: &fakeLabel, Locked: &locked}
+			if r.Method == http.MethodDelete {
+				response.Locked = &unlocked
+			}
+			_ = json.NewEncoder(w).Encode(response)
+		}
+	})
+
+	cases := map[string]struct {
+		reason  string
+		handler http.Handler
+		args    args
+		want    want
+	}{
+		"LockKeyValueSucessfully": {
+			reason:  "Should return the locked KeyValue",
+			handler: handler,
+			args: args{
+				key:   fakeKey,
+				label: fakeLabel,
+			},
+			want: want{
+				kv:  KeyValue{Key: &fakeKey, Label: &fakeLabel, Locked: &locked},
+				err: nil,
+			},
+		},
+		"UnlockKeyValueSucessfully": {
+			reason:  "Should return the unlocked KeyValue",
+			handler: handler,
+			args: args{
+				key:    fakeKey,
+				label:  fakeLabel,
+				unlock: true,
+			},
+			want: want{
+				kv:  KeyValue{Key: &fakeKey, Label: &fakeLabel, Locked: &unlocked},
+				err: nil,
+			},
+		},
+		"LockKeyValueInternalError": {
+			reason: "Should return an error if the request returns Status Code greater than 399",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.Contains(r.URL.String(), "locks") {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}),
+			args: args{
+				key:   fakeKey,
+				label: fakeLabel,
+			},
+			want: want{
+				kv:  KeyValue{},
+				err: errInternal,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+			args := NewClientAzureADArgs{ClientID: fakeIDs, TenantID: fakeIDs, ClientSecret: fakeIDs, ResourceEndpoint: server.URL, AADEndpoint: server.URL}
+			c, _ := NewClientAzureAD(args)
+
+			lock := c.LockKeyValue
+			if tc.args.unlock {
+				lock = c.UnlockKeyValue
+			}
+			got, err := lock(context.Background(), tc.args.key, tc.args.label)
+
+			if diff := cmp.Diff(tc.want.kv, got); diff != "" {
+				t.Errorf("LockKeyValue(...): -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("LockKeyValue(...): -want error, +got error:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestClientContextCanceled(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		call   func(ctx context.Context, c Client) error
+	}{
+		"ListKeyValues": {
+			reason: "Should abort the list request when the context is canceled",
+			call: func(ctx context.Context, c Client) error {
+				_, err := c.ListKeyValuesWithContext(ctx, ListKeyValuesArgs{})
+				return err
+			},
+		},
+		"GetKeyValue": {
+			reason: "Should abort the GET request when the context is canceled",
+			call: func(ctx context.Context, c Client) error {
+				_, err := c.GetKeyValueWithContext(ctx, fakeKey, fakeLabel)
+				return err
+			},
+		},
+		"CreateOrUpdateKeyValue": {
+			reason: "Should abort the PUT request when the context is canceled",
+			call: func(ctx context.Context, c Client) error {
+				_, err := c.CreateOrUpdateKeyValueWithContext(ctx, CreateOrUpdateKeyValueArgs{Key: fakeKey})
+				return err
+			},
+		},
+		"DeleteKeyValue": {
+			reason: "Should abort the DELETE request when the context is canceled",
+			call: func(ctx context.Context, c Client) error {
+				return c.DeleteKeyValueWithContext(ctx, fakeKey, fakeLabel)
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("%s: unexpected request %s", tc.reason, r.URL)
+			}))
+			defer server.Close()
+			c := NewClient(server.URL, autorest.NullAuthorizer{})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			if err := tc.call(ctx, c); !errors.Is(err, context.Canceled) {
+				t.Errorf("%s(...): want context.Canceled, got: %v", name, err)
+			}
+		})
+	}
+}
+
+func TestGetNextLink(t *testing.T) {
+	type args struct {
+		links []string
+	}
+	type want struct {
+		nextLink string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoLink": {
+			reason: "Should return an empty link without Link header",
+			args:   args{},
+			want:   want{nextLink: ""},
+		},
+		"Next": {
+			reason: "Should return the next link",
+			args:   args{links: []string{`</kv?after=a&api-version=1.0>; rel="next"`}},
+			want:   want{nextLink: "/kv?after=a&api-version=1.0"},
+		},
+		"UnquotedRel": {
+			reason: "Should accept an unquoted relation type",
+			args:   args{links: []string{`</kv?after=a>;rel=next`}},
+			want:   want{nextLink: "/kv?after=a"},
+		},
+		"CommaInLink": {
+			reason: "Should keep the commas of the link",
+			args:   args{links: []string{`</kv?after=a&$select=key,etag>; rel="next"`}},
+			want:   want{nextLink: "/kv?after=a&$select=key,etag"},
+		},
+		"MultipleRelationTypes": {
+			reason: "Should find next among the relation types",
+			args:   args{links: []string{`</kv?after=a>; rel="prev next"`}},
+			want:   want{nextLink: "/kv?after=a"},
+		},
+		"OtherRelationType": {
+			reason: "Should ignore relation types only starting with next",
+			args:   args{links: []string{`</kv?after=a>; rel="next-archive"`}},
+			want:   want{nextLink: ""},
+		},
+		"MultipleLinks": {
+			reason: "Should find the next link among several links",
+			args: args{links: []string{
+				`</kv?before=a>; rel="prev"; title="a, b", </kv?after=b>; rel="next"`,
+			}},
+			want: want{nextLink: "/kv?after=b"},
+		},
+		"MultipleHeaders": {
+			reason: "Should find the next link among several Link headers",
+			args:   args{links: []string{`</kv?before=a>; rel="prev"`, `</kv?after=b>; rel="next"`}},
+			want:   want{nextLink: "/kv?after=b"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			response := &http.Response{Header: http.Header{"Link": tc.args.links}}
+			got := getNextLink(response)
+			if diff := cmp.Diff(tc.want.nextLink, got); diff != "" {
+				t.Errorf("getNextLink(...): %s: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
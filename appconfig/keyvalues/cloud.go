@@ -0,0 +1,46 @@
+package keyvalues
+
+// Cloud configures the Azure AD authority and the token audience used to
+// authenticate against App Configuration in a given Azure cloud.
+//
+// Audience is the resource the access tokens are requested for. If it is
+// empty, the App Configuration endpoint is used as audience.
+type Cloud struct {
+	ActiveDirectoryEndpoint string
+	Audience                string
+}
+
+// Azure clouds supported by App Configuration. A custom Cloud can be used
+// for private authorities or audiences.
+var (
+	AzurePublicCloud = Cloud{
+		ActiveDirectoryEndpoint: "https://login.microsoftonline.com/",
+		Audience:                "https://azconfig.io",
+	}
+	AzureChinaCloud = Cloud{
+		ActiveDirectoryEndpoint: "https://login.chinacloudapi.cn/",
+		Audience:                "https://azconfig.azure.cn",
+	}
+	AzureUSGovernmentCloud = Cloud{
+		ActiveDirectoryEndpoint: "https://login.microsoftonline.us/",
+		Audience:                "https://azconfig.azure.us",
+	}
+)
+
+// resolve returns the Azure AD endpoint and the token audience, giving
+// precedence to an explicit Azure AD endpoint. Empty values fall back to
+// the Azure public cloud authority and to the App Configuration endpoint.
+func (c Cloud) resolve(aadEndpoint, endpoint string) (string, string) {
+	if aadEndpoint == "" {
+		aadEndpoint = c.ActiveDirectoryEndpoint
+	}
+	if aadEndpoint == "" {
+		aadEndpoint = AzurePublicCloud.ActiveDirectoryEndpoint
+	}
+
+	audience := c.Audience
+	if audience == "" {
+		audience = endpoint
+	}
+	return aadEndpoint, audience
+}
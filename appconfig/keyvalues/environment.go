@@ -0,0 +1,102 @@
+package keyvalues
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Environment variables read by NewClientFromEnvironment.
+const (
+	EnvConnectionString   = "AZURE_APPCONFIG_CONNECTION_STRING"
+	EnvClientID           = "AZURE_CLIENT_ID"
+	EnvClientSecret       = "AZURE_CLIENT_SECRET"
+	EnvTenantID           = "AZURE_TENANT_ID"
+	EnvFederatedTokenFile = "AZURE_FEDERATED_TOKEN_FILE"
+	EnvAuthorityHost      = "AZURE_AUTHORITY_HOST"
+)
+
+// CredentialSource identifies the credential used by a Client created
+// with NewClientFromEnvironment.
+type CredentialSource string
+
+// Credential sources, in the order they are tried by
+// NewClientFromEnvironment.
+const (
+	CredentialSourceConnectionString CredentialSource = "ConnectionString"
+	CredentialSourceClientSecret     CredentialSource = "ClientSecret"
+	CredentialSourceWorkloadIdentity CredentialSource = "WorkloadIdentity"
+	CredentialSourceManagedIdentity  CredentialSource = "ManagedIdentity"
+	CredentialSourceAzureCLI         CredentialSource = "AzureCLI"
+)
+
+// NewClientFromEnvironment creates a Client from the first credential
+// available in the environment, reporting which source was used. The
+// sources are tried in order:
+//
+// 1. ConnectionString: AZURE_APPCONFIG_CONNECTION_STRING
+//
+// 2. ClientSecret: AZURE_CLIENT_ID, AZURE_CLIENT_SECRET, AZURE_TENANT_ID
+//
+// 3. WorkloadIdentity: AZURE_CLIENT_ID, AZURE_TENANT_ID,
+// AZURE_FEDERATED_TOKEN_FILE
+//
+// 4. ManagedIdentity: user-assigned if AZURE_CLIENT_ID is set
+//
+// 5. AzureCLI
+//
+// AZURE_AUTHORITY_HOST optionally overrides the Azure AD endpoint of the
+// Cloud. The connection string carries its own endpoint, the others use
+// the provided ResourceEndpoint.
+func NewClientFromEnvironment(args NewClientFromEnvironmentArgs, opts ...ClientOption) (Client, CredentialSource, error) {
+	if connectionString := os.Getenv(EnvConnectionString); connectionString != "" {
+		client, err := NewClientFromConnectionString(connectionString, opts...)
+		return client, CredentialSourceConnectionString, err
+	}
+
+	clientID := os.Getenv(EnvClientID)
+	tenantID := os.Getenv(EnvTenantID)
+	if secret := os.Getenv(EnvClientSecret); clientID != "" && tenantID != "" && secret != "" {
+		client, err := NewClientAzureAD(NewClientAzureADArgs{
+			ClientID:         clientID,
+			ClientSecret:     secret,
+			TenantID:         tenantID,
+			AADEndpoint:      os.Getenv(EnvAuthorityHost),
+			ResourceEndpoint: args.ResourceEndpoint,
+			Cloud:            args.Cloud,
+		}, opts...)
+		return client, CredentialSourceClientSecret, err
+	}
+
+	if tokenFile := os.Getenv(EnvFederatedTokenFile); clientID != "" && tenantID != "" && tokenFile != "" {
+		client, err := NewClientWorkloadIdentity(NewClientWorkloadIdentityArgs{
+			ClientID:         clientID,
+			TenantID:         tenantID,
+			TokenFilePath:    tokenFile,
+			AADEndpoint:      os.Getenv(EnvAuthorityHost),
+			ResourceEndpoint: args.ResourceEndpoint,
+			Cloud:            args.Cloud,
+		}, opts...)
+		return client, CredentialSourceWorkloadIdentity, err
+	}
+
+	var failures []string
+	client, err := NewClientManagedIdentity(NewClientManagedIdentityArgs{
+		ClientID:         clientID,
+		ResourceEndpoint: args.ResourceEndpoint,
+		Cloud:            args.Cloud,
+	}, opts...)
+	if err == nil {
+		return client, CredentialSourceManagedIdentity, nil
+	}
+	failures = append(failures, fmt.Sprintf("%s: %v", CredentialSourceManagedIdentity, err))
+
+	_, audience := args.Cloud.resolve("", args.ResourceEndpoint)
+	client, err = newClientCli(args.ResourceEndpoint, audience, opts...)
+	if err == nil {
+		return client, CredentialSourceAzureCLI, nil
+	}
+	failures = append(failures, fmt.Sprintf("%s: %v", CredentialSourceAzureCLI, err))
+
+	return nil, "", fmt.Errorf("no credential available in the environment: %s", strings.Join(failures, "; "))
+}
@@ -0,0 +1,133 @@
+package keyvalues
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/stone-payments/appconfig-go-sdk/appconfig/test"
+)
+
+func TestNewClientFromEnvironment(t *testing.T) {
+	type args struct {
+		env map[string]string
+		NewClientFromEnvironmentArgs
+	}
+	type want struct {
+		source CredentialSource
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ConnectionString": {
+			reason: "Should prefer the connection string over any other credential",
+			args: args{
+				env: map[string]string{
+					EnvConnectionString: "Endpoint=https://fake.azconfig.io;Id=fakeID;Secret=" + fakeAccessSecret,
+					EnvClientID:         fakeIDs,
+					EnvClientSecret:     fakeIDs,
+					EnvTenantID:         fakeIDs,
+				},
+			},
+			want: want{
+				source: CredentialSourceConnectionString,
+				err:    nil,
+			},
+		},
+		"ClientSecret": {
+			reason: "Should use the client secret over workload identity",
+			args: args{
+				env: map[string]string{
+					EnvClientID:           fakeIDs,
+					EnvClientSecret:       fakeIDs,
+					EnvTenantID:           fakeIDs,
+					EnvFederatedTokenFile: "/fake/token",
+				},
+				NewClientFromEnvironmentArgs: NewClientFromEnvironmentArgs{
+					ResourceEndpoint: "https://fake.azconfig.io",
+				},
+			},
+			want: want{
+				source: CredentialSourceClientSecret,
+				err:    nil,
+			},
+		},
+		"WorkloadIdentity": {
+			reason: "Should use workload identity if a federated token file is set",
+			args: args{
+				env: map[string]string{
+					EnvClientID:           fakeIDs,
+					EnvTenantID:           fakeIDs,
+					EnvFederatedTokenFile: "/fake/token",
+				},
+				NewClientFromEnvironmentArgs: NewClientFromEnvironmentArgs{
+					ResourceEndpoint: "https://fake.azconfig.io",
+				},
+			},
+			want: want{
+				source: CredentialSourceWorkloadIdentity,
+				err:    nil,
+			},
+		},
+		"ManagedIdentity": {
+			reason: "Should fall back to managed identity",
+			args: args{
+				env: map[string]string{
+					"MSI_ENDPOINT": "http://localhost/msi/token",
+				},
+				NewClientFromEnvironmentArgs: NewClientFromEnvironmentArgs{
+					ResourceEndpoint: "https://fake.azconfig.io",
+				},
+			},
+			want: want{
+				source: CredentialSourceManagedIdentity,
+				err:    nil,
+			},
+		},
+		"ManagedIdentityCloudAudience": {
+			reason: "Should request the managed identity tokens for the Cloud audience",
+			args: args{
+				env: map[string]string{
+					"MSI_ENDPOINT": "http://localhost/msi/token",
+				},
+				NewClientFromEnvironmentArgs: NewClientFromEnvironmentArgs{
+					Cloud: AzureUSGovernmentCloud,
+				},
+			},
+			want: want{
+				source: CredentialSourceManagedIdentity,
+				err:    nil,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			for _, key := range []string{EnvConnectionString, EnvClientID, EnvClientSecret, EnvTenantID, EnvFederatedTokenFile, EnvAuthorityHost, "MSI_ENDPOINT"} {
+				value, ok := os.LookupEnv(key)
+				os.Unsetenv(key)
+				if ok {
+					defer os.Setenv(key, value)
+				}
+			}
+			for key, value := range tc.args.env {
+				os.Setenv(key, value)
+				defer os.Unsetenv(key)
+			}
+
+			_, source, err := NewClientFromEnvironment(tc.args.NewClientFromEnvironmentArgs)
+
+			if diff := cmp.Diff(tc.want.source, source); diff != "" {
+				t.Errorf("NewClientFromEnvironment(...): -want source, +got source:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("NewClientFromEnvironment(...): -want error, +got error:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package keyvalues
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+const (
+	requestIDHeader = "x-ms-request-id"
+	keyLockedType   = "https://azconfig.io/errors/key-locked"
+)
+
+// ErrNotModified is returned by GetKeyValueWithArgs when the KeyValue
+// still matches the provided IfNoneMatch Etag. No KeyValue is decoded.
+var ErrNotModified = errors.New("keyvalues: KeyValue not modified")
+
+// ErrSnapshotFilters is returned when listing the KeyValues of a Snapshot
+// with key, label or tags filters, which Snapshots do not support.
+var ErrSnapshotFilters = errors.New("keyvalues: Snapshot can not be combined with Key, Label, Keys, Labels or Tags")
+
+// ResponseError is returned by the Client methods when App Configuration
+// answers with a Status Code greater than 399. The problem details are
+// decoded from the application/problem+json response body, if any.
+//
+// Use errors.As to inspect it, or one of the IsNotFound, IsConflict,
+// IsPreconditionFailed and IsLocked helpers.
+type ResponseError struct {
+	StatusCode int    `json:"-"`
+	Status     string `json:"-"`
+	Type       string `json:"type,omitempty"`
+	Title      string `json:"title,omitempty"`
+	Detail     string `json:"detail,omitempty"`
+	Name       string `json:"name,omitempty"`
+	RequestID  string `json:"-"`
+	Body       string `json:"-"`
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("ERROR: %s - Response Body: %s", e.Status, e.Body)
+}
+
+// newResponseError reads and closes the response body, building a
+// ResponseError from it.
+func newResponseError(resp *http.Response) *ResponseError {
+	s, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	respErr := &ResponseError{}
+	if strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		// The problem details are best effort, the raw body is always kept.
+		_ = json.Unmarshal(s, respErr)
+	}
+	respErr.StatusCode = resp.StatusCode
+	respErr.Status = resp.Status
+	respErr.RequestID = resp.Header.Get(requestIDHeader)
+	respErr.Body = string(s)
+	return respErr
+}
+
+// IsNotFound reports whether err is a ResponseError with
+// Status Code 404 Not Found.
+func IsNotFound(err error) bool {
+	return hasStatusCode(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is a ResponseError with
+// Status Code 409 Conflict.
+func IsConflict(err error) bool {
+	return hasStatusCode(err, http.StatusConflict)
+}
+
+// IsPreconditionFailed reports whether err is a ResponseError with
+// Status Code 412 Precondition Failed, e.g. when an ETag does not match.
+func IsPreconditionFailed(err error) bool {
+	return hasStatusCode(err, http.StatusPreconditionFailed)
+}
+
+// IsLocked reports whether err is a ResponseError caused by
+// modifying a locked Key-Value.
+func IsLocked(err error) bool {
+	var respErr *ResponseError
+	return errors.As(err, &respErr) &&
+		respErr.StatusCode == http.StatusConflict &&
+		respErr.Type == keyLockedType
+}
+
+func hasStatusCode(err error, statusCode int) bool {
+	var respErr *ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == statusCode
+}
@@ -0,0 +1,143 @@
+package keyvalues
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var fakeProblem = `{"type":"https://azconfig.io/errors/key-locked","title":"Modifying key 'fakeKey' is not allowed","name":"fakeKey","detail":"The key is read-only.","status":409}`
+
+func TestNewResponseError(t *testing.T) {
+	type args struct {
+		resp *http.Response
+	}
+	type want struct {
+		err *ResponseError
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ProblemDetails": {
+			reason: "Should decode the problem+json body and the request ID",
+			args: args{
+				resp: &http.Response{
+					StatusCode: http.StatusConflict,
+					Status:     "409 Conflict",
+					Header: http.Header{
+						"Content-Type":    []string{"application/problem+json; charset=utf-8"},
+						"X-Ms-Request-Id": []string{fakeIDs},
+					},
+					Body: ioutil.NopCloser(strings.NewReader(fakeProblem)),
+				},
+			},
+			want: want{
+				err: &ResponseError{
+					StatusCode: http.StatusConflict,
+					Status:     "409 Conflict",
+					Type:       "https://azconfig.io/errors/key-locked",
+					Title:      "Modifying key 'fakeKey' is not allowed",
+					Detail:     "The key is read-only.",
+					Name:       fakeKey,
+					RequestID:  fakeIDs,
+					Body:       fakeProblem,
+				},
+			},
+		},
+		"PlainBody": {
+			reason: "Should keep the raw body if it is not JSON",
+			args: args{
+				resp: &http.Response{
+					StatusCode: http.StatusInternalServerError,
+					Status:     "500 Internal Server Error",
+					Header:     http.Header{"Content-Type": []string{"text/plain"}},
+					Body:       ioutil.NopCloser(strings.NewReader("oops")),
+				},
+			},
+			want: want{
+				err: &ResponseError{
+					StatusCode: http.StatusInternalServerError,
+					Status:     "500 Internal Server Error",
+					Body:       "oops",
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := newResponseError(tc.args.resp)
+			if diff := cmp.Diff(tc.want.err, got); diff != "" {
+				t.Errorf("newResponseError(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestErrorHelpers(t *testing.T) {
+	type want struct {
+		notFound           bool
+		conflict           bool
+		preconditionFailed bool
+		locked             bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   want
+	}{
+		"NotFound": {
+			reason: "Should detect a 404 response",
+			err:    &ResponseError{StatusCode: http.StatusNotFound},
+			want:   want{notFound: true},
+		},
+		"Conflict": {
+			reason: "Should detect a 409 response",
+			err:    &ResponseError{StatusCode: http.StatusConflict},
+			want:   want{conflict: true},
+		},
+		"Locked": {
+			reason: "Should detect a 409 response caused by a locked key",
+			err:    &ResponseError{StatusCode: http.StatusConflict, Type: keyLockedType},
+			want:   want{conflict: true, locked: true},
+		},
+		"PreconditionFailed": {
+			reason: "Should detect a 412 response",
+			err:    &ResponseError{StatusCode: http.StatusPreconditionFailed},
+			want:   want{preconditionFailed: true},
+		},
+		"Wrapped": {
+			reason: "Should unwrap errors wrapping a ResponseError",
+			err:    fmt.Errorf("get: %w", &ResponseError{StatusCode: http.StatusNotFound}),
+			want:   want{notFound: true},
+		},
+		"OtherError": {
+			reason: "Should not match errors other than ResponseError",
+			err:    errors.New("boom"),
+			want:   want{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := want{
+				notFound:           IsNotFound(tc.err),
+				conflict:           IsConflict(tc.err),
+				preconditionFailed: IsPreconditionFailed(tc.err),
+				locked:             IsLocked(tc.err),
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("%s: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package keyvalues
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// NullLabel matches the KeyValues without a label when used
+// in ListKeyValuesArgs.Labels.
+const NullLabel = `\0`
+
+var (
+	filterEscaper = strings.NewReplacer(`\`, `\\`, `,`, `\,`, `*`, `\*`)
+	tagEscaper    = strings.NewReplacer(`\`, `\\`, `,`, `\,`, `*`, `\*`, `=`, `\=`)
+)
+
+// joinFilters builds a comma-separated App Configuration filter. The raw
+// filter is kept as is, while each of the values is escaped.
+func joinFilters(raw string, values []string) string {
+	var filters []string
+	if raw != "" {
+		filters = append(filters, raw)
+	}
+	for _, value := range values {
+		filters = append(filters, escapeFilter(value))
+	}
+	return strings.Join(filters, ",")
+}
+
+// escapeFilter escapes the reserved characters of a filter value. A
+// trailing "*" is kept as a wildcard and NullLabel is kept as is.
+func escapeFilter(value string) string {
+	if value == NullLabel {
+		return value
+	}
+
+	wildcard := strings.HasSuffix(value, "*")
+	value = filterEscaper.Replace(strings.TrimSuffix(value, "*"))
+	if wildcard {
+		value += "*"
+	}
+	return value
+}
+
+// withTags returns the decorators that filter the KeyValues having all the
+// provided tags. Tag filters require a newer API version.
+func withTags(tags map[string]string) []autorest.PrepareDecorator {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	filters := make([]string, 0, len(tags))
+	for name, value := range tags {
+		filters = append(filters, tagEscaper.Replace(name)+"="+tagEscaper.Replace(value))
+	}
+	sort.Strings(filters)
+	// WithQueryParameters unescapes the values, so they are encoded first.
+	for i, filter := range filters {
+		filters[i] = autorest.Encode("query", filter)
+	}
+
+	return []autorest.PrepareDecorator{
+		autorest.WithQueryParameters(map[string]interface{}{
+			"tags":        filters,
+			"api-version": latestAPIVersion,
+		}),
+	}
+}
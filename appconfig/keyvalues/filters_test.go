@@ -0,0 +1,63 @@
+package keyvalues
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestJoinFilters(t *testing.T) {
+	type args struct {
+		raw    string
+		values []string
+	}
+	type want struct {
+		filter string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Empty": {
+			reason: "Should return an empty filter if nothing is provided",
+			args:   args{},
+			want:   want{filter: ""},
+		},
+		"RawOnly": {
+			reason: "Should keep the raw filter as is",
+			args:   args{raw: `app:\,*`},
+			want:   want{filter: `app:\,*`},
+		},
+		"EscapedValues": {
+			reason: "Should escape the reserved characters but the trailing wildcard",
+			args:   args{values: []string{`a,b`, `c\d`, `e*f*`}},
+			want:   want{filter: `a\,b,c\\d,e\*f*`},
+		},
+		"PlusAndPercent": {
+			reason: "Should keep the plus and percent signs, which are query encoded later",
+			args:   args{values: []string{"c++", "100%"}},
+			want:   want{filter: "c++,100%"},
+		},
+		"NullLabel": {
+			reason: "Should keep the null label unescaped",
+			args:   args{values: []string{"prod", NullLabel}},
+			want:   want{filter: `prod,\0`},
+		},
+		"RawAndValues": {
+			reason: "Should join the raw filter and the escaped values",
+			args:   args{raw: "app:*", values: []string{"other"}},
+			want:   want{filter: "app:*,other"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := joinFilters(tc.args.raw, tc.args.values)
+			if diff := cmp.Diff(tc.want.filter, got); diff != "" {
+				t.Errorf("joinFilters(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package keyvalues
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+const hmacSignedHeaders = "x-ms-date;host;x-ms-content-sha256"
+
+// connectionString holds the fields of an App Configuration access key
+// connection string, e.g. "Endpoint=https://...;Id=...;Secret=...".
+type connectionString struct {
+	Endpoint string
+	ID       string
+	Secret   string
+}
+
+func parseConnectionString(value string) (connectionString, error) {
+	var cs connectionString
+	for _, part := range strings.Split(value, ";") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch strings.ToLower(kv[0]) {
+		case "endpoint":
+			cs.Endpoint = strings.TrimRight(kv[1], "/")
+		case "id":
+			cs.ID = kv[1]
+		case "secret":
+			cs.Secret = kv[1]
+		}
+	}
+
+	if cs.Endpoint == "" || cs.ID == "" || cs.Secret == "" {
+		return connectionString{}, fmt.Errorf("invalid connection string: Endpoint, Id and Secret are required")
+	}
+	return cs, nil
+}
+
+// HMACAuthorizer implements autorest.Authorizer, signing the requests with
+// an App Configuration access key using the HMAC-SHA256 scheme.
+type HMACAuthorizer struct {
+	credential string
+	secret     []byte
+	now        func() time.Time
+}
+
+// NewHMACAuthorizer creates an HMACAuthorizer from an access key ID and
+// its base64 encoded secret.
+func NewHMACAuthorizer(id, secret string) (*HMACAuthorizer, error) {
+	key, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid access key secret: %w", err)
+	}
+	return &HMACAuthorizer{
+		credential: id,
+		secret:     key,
+		now:        time.Now,
+	}, nil
+}
+
+// WithAuthorization returns a PrepareDecorator that signs the request,
+// adding the x-ms-date, x-ms-content-sha256 and Authorization headers.
+func (a *HMACAuthorizer) WithAuthorization() autorest.PrepareDecorator {
+	return func(p autorest.Preparer) autorest.Preparer {
+		return autorest.PreparerFunc(func(r *http.Request) (*http.Request, error) {
+			r, err := p.Prepare(r)
+			if err == nil {
+				err = a.sign(r)
+			}
+			return r, err
+		})
+	}
+}
+
+func (a *HMACAuthorizer) sign(r *http.Request) error {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(r.Body); err != nil {
+			return err
+		}
+		r.Body.Close()
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		r.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	hash := sha256.Sum256(body)
+	contentHash := base64.StdEncoding.EncodeToString(hash[:])
+	date := a.now().UTC().Format(http.TimeFormat)
+	stringToSign := fmt.Sprintf("%s\n%s\n%s;%s;%s", r.Method, r.URL.RequestURI(), date, r.URL.Host, contentHash)
+
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte(stringToSign))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	if r.Header == nil {
+		r.Header = make(http.Header)
+	}
+	r.Header.Set("x-ms-date", date)
+	r.Header.Set("x-ms-content-sha256", contentHash)
+	r.Header.Set("Authorization", fmt.Sprintf("HMAC-SHA256 Credential=%s&SignedHeaders=%s&Signature=%s", a.credential, hmacSignedHeaders, signature))
+	return nil
+}
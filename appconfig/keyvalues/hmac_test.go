@@ -0,0 +1,125 @@
+package keyvalues
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/stone-payments/appconfig-go-sdk/appconfig/test"
+)
+
+var fakeAccessSecret = "ZmFrZVNlY3JldA=="
+
+func TestNewClientFromConnectionString(t *testing.T) {
+	type args struct {
+		connectionString string
+	}
+	type want struct {
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"CreateNewClientSuccess": {
+			reason: "Should create a new Client sucessfully",
+			args: args{
+				connectionString: "Endpoint=https://my-config.azconfig.io;Id=fakeID;Secret=" + fakeAccessSecret,
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		"MissingSecret": {
+			reason: "Should return an error if a field is missing",
+			args: args{
+				connectionString: "Endpoint=https://my-config.azconfig.io;Id=fakeID",
+			},
+			want: want{
+				err: errors.New("invalid connection string: Endpoint, Id and Secret are required"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewClientFromConnectionString(tc.args.connectionString)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("NewClientFromConnectionString(...): -want error, +got error:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHMACAuthorizer(t *testing.T) {
+	auth, err := NewHMACAuthorizer("fakeID", fakeAccessSecret)
+	if err != nil {
+		t.Fatalf("NewHMACAuthorizer(...): unexpected error: %v", err)
+	}
+	auth.now = func() time.Time {
+		return time.Date(2021, time.August, 2, 15, 4, 5, 0, time.UTC)
+	}
+
+	body := `{"key":"fakeKey","value":"fakeValue"}`
+	req, err := autorest.CreatePreparer(
+		autorest.WithBaseURL("https://my-config.azconfig.io"),
+		autorest.WithPathParameters("/kv/{key}", map[string]interface{}{"key": fakeKey}),
+		autorest.WithQueryParameters(map[string]interface{}{"label": fakeLabel, "api-version": apiVersion}),
+		autorest.AsPut(),
+		autorest.WithString(body),
+		auth.WithAuthorization(),
+	).Prepare(&http.Request{})
+	if err != nil {
+		t.Fatalf("WithAuthorization(...): unexpected error: %v", err)
+	}
+
+	want := http.Header{
+		"X-Ms-Date":           []string{"Mon, 02 Aug 2021 15:04:05 GMT"},
+		"X-Ms-Content-Sha256": []string{"O6rOKEqp2GsZp+XMVqpFM/gn2sdYmMVXBrJJlhxbD2c="},
+		"Authorization":       []string{"HMAC-SHA256 Credential=fakeID&SignedHeaders=x-ms-date;host;x-ms-content-sha256&Signature=Y+uCKWAOBvUprSfqaI+KQfHVH1jr5S51E98eUIe1/f0="},
+	}
+	if diff := cmp.Diff(want, req.Header); diff != "" {
+		t.Errorf("WithAuthorization(...): -want headers, +got headers:\n%s", diff)
+	}
+
+	got, _ := ioutil.ReadAll(req.Body)
+	if diff := cmp.Diff(body, string(got)); diff != "" {
+		t.Errorf("WithAuthorization(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+func TestConnectionStringClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body.Close()
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "HMAC-SHA256 Credential=fakeID&") || r.Header.Get("x-ms-date") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(KeyValue{Key: &fakeKey})
+	}))
+	defer server.Close()
+
+	c, err := NewClientFromConnectionString("Endpoint=" + server.URL + ";Id=fakeID;Secret=" + fakeAccessSecret)
+	if err != nil {
+		t.Fatalf("NewClientFromConnectionString(...): unexpected error: %v", err)
+	}
+
+	got, err := c.GetKeyValue(fakeKey, fakeLabel)
+	if diff := cmp.Diff(KeyValue{Key: &fakeKey}, got); diff != "" {
+		t.Errorf("GetKeyValue(...): -want, +got:\n%s", diff)
+	}
+	if err != nil {
+		t.Errorf("GetKeyValue(...): unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+package keyvalues
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/go-autorest/autorest/adal"
+)
+
+const clientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// federatedTokenSecret implements adal.ServicePrincipalSecret, using the
+// federated token file projected by workload identity as client assertion.
+// The file is read on each token refresh, as it is rotated by the platform.
+type federatedTokenSecret struct {
+	tokenFilePath string
+}
+
+// SetAuthenticationValues populates the form submitted during the token
+// acquisition with the federated token.
+func (s *federatedTokenSecret) SetAuthenticationValues(spt *adal.ServicePrincipalToken, v *url.Values) error {
+	token, err := ioutil.ReadFile(s.tokenFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read the federated token file: %w", err)
+	}
+
+	v.Set("client_assertion", strings.TrimSpace(string(token)))
+	v.Set("client_assertion_type", clientAssertionType)
+	return nil
+}
@@ -0,0 +1,123 @@
+package keyvalues
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const redactedValue = "REDACTED"
+
+var keyVaultRefMediaType = strings.Split(keyVaultRefContentType, ";")[0]
+
+// Logger receives the Client logs as a message followed by alternating
+// attribute names and values. It is satisfied by *slog.Logger.
+//
+// Each request attempt is logged with its method, URL, duration, status
+// and request ID, at the Debug level, or at the Error level if it failed.
+// The request and response bodies are logged at the Debug level, with the
+// values of the Key Vault references and of the keys matching the Client
+// RedactedKeys replaced by REDACTED.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// loggingPolicy is the pipeline Policy logging each request attempt, if a
+// Logger is set.
+func (client *ClientImpl) loggingPolicy(req *http.Request, next Next) (*http.Response, error) {
+	if client.Logger == nil {
+		return next(req)
+	}
+
+	if err := rewindable(req); err != nil {
+		return nil, err
+	}
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			s, _ := ioutil.ReadAll(body)
+			client.logBody("appconfig request body", s)
+		}
+	}
+
+	start := time.Now()
+	resp, err := next(req)
+	attrs := []interface{}{
+		"method", req.Method,
+		"url", req.URL.String(),
+		"duration", time.Since(start),
+	}
+	if err != nil {
+		client.Logger.Error("appconfig request failed", append(attrs, "error", err)...)
+		return resp, err
+	}
+
+	attrs = append(attrs, "status", resp.StatusCode, "request_id", resp.Header.Get(requestIDHeader))
+	if resp.StatusCode >= 400 {
+		client.Logger.Error("appconfig request failed", attrs...)
+		return resp, nil
+	}
+	client.Logger.Debug("appconfig request", attrs...)
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		client.Logger.Error("appconfig response body failed", append(attrs, "error", err)...)
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	client.logBody("appconfig response body", body)
+	return resp, nil
+}
+
+// logBody logs a JSON body, redacting the secret KeyValue values.
+func (client *ClientImpl) logBody(msg string, body []byte) {
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return
+	}
+	client.redact(doc)
+
+	redacted, err := json.Marshal(doc)
+	if err != nil {
+		return
+	}
+	client.Logger.Debug(msg, "body", string(redacted))
+}
+
+// redact replaces the secret values of a decoded KeyValue or KeyValues.
+func (client *ClientImpl) redact(doc interface{}) {
+	object, ok := doc.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if items, ok := object["items"].([]interface{}); ok {
+		for _, item := range items {
+			client.redact(item)
+		}
+		return
+	}
+	if _, ok := object["value"]; ok && client.isSecret(object) {
+		object["value"] = redactedValue
+	}
+}
+
+// isSecret reports whether a decoded KeyValue is a Key Vault reference or
+// has a key matching the RedactedKeys.
+func (client *ClientImpl) isSecret(kv map[string]interface{}) bool {
+	contentType, _ := kv["content_type"].(string)
+	if strings.TrimSpace(strings.Split(contentType, ";")[0]) == keyVaultRefMediaType {
+		return true
+	}
+
+	key, _ := kv["key"].(string)
+	for _, pattern := range client.RedactedKeys {
+		if pattern.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
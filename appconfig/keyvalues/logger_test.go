@@ -0,0 +1,116 @@
+package keyvalues
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestLogger(t *testing.T) {
+	type args struct {
+		redactedKeys []*regexp.Regexp
+		call         func(ctx context.Context, c Client) error
+	}
+	type want struct {
+		entries []logEntry
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		args    args
+		want    want
+	}{
+		"RedactKeyVaultReference": {
+			reason: "Should redact the values of the Key Vault references",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(requestIDHeader, fakeIDs)
+				fmt.Fprintf(w, `{"key":"secret","content_type":%q,"value":"{\"uri\":\"https://vault\"}"}`, keyVaultRefContentType)
+			},
+			args: args{call: func(ctx context.Context, c Client) error {
+				_, err := c.GetKeyValueWithContext(ctx, "secret", "")
+				return err
+			}},
+			want: want{entries: []logEntry{
+				{Level: "DEBUG", Message: "appconfig request", Args: map[string]interface{}{
+					"method": http.MethodGet, "status": http.StatusOK, "request_id": fakeIDs,
+				}},
+				{Level: "DEBUG", Message: "appconfig response body", Args: map[string]interface{}{
+					"body": fmt.Sprintf(`{"content_type":%q,"key":"secret","value":"REDACTED"}`, keyVaultRefContentType),
+				}},
+			}},
+		},
+		"RedactKeys": {
+			reason: "Should redact the values of the keys matching the patterns, in requests and responses",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"items":[{"key":"db:password","value":"hunter2"},{"key":"db:host","value":"localhost"}]}`)
+			},
+			args: args{
+				redactedKeys: []*regexp.Regexp{regexp.MustCompile(`password$`)},
+				call: func(ctx context.Context, c Client) error {
+					_, err := c.CreateOrUpdateKeyValueWithContext(ctx, CreateOrUpdateKeyValueArgs{Key: "db:password", Value: "hunter2"})
+					return err
+				},
+			},
+			want: want{entries: []logEntry{
+				{Level: "DEBUG", Message: "appconfig request body", Args: map[string]interface{}{
+					"body": `{"key":"db:password","value":"REDACTED"}`,
+				}},
+				{Level: "DEBUG", Message: "appconfig request", Args: map[string]interface{}{
+					"method": http.MethodPut, "status": http.StatusOK, "request_id": "",
+				}},
+				{Level: "DEBUG", Message: "appconfig response body", Args: map[string]interface{}{
+					"body": `{"items":[{"key":"db:password","value":"REDACTED"},{"key":"db:host","value":"localhost"}]}`,
+				}},
+			}},
+		},
+		"Failure": {
+			reason: "Should log the failed requests as errors",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(requestIDHeader, fakeIDs)
+				w.WriteHeader(http.StatusNotFound)
+			},
+			args: args{call: func(ctx context.Context, c Client) error {
+				return c.DeleteKeyValueWithContext(ctx, fakeKey, fakeLabel)
+			}},
+			want: want{entries: []logEntry{
+				{Level: "ERROR", Message: "appconfig request failed", Args: map[string]interface{}{
+					"method": http.MethodDelete, "status": http.StatusNotFound, "request_id": fakeIDs,
+				}},
+			}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+
+			logger := &fakeLogger{}
+			c := NewClient(server.URL, autorest.NullAuthorizer{},
+				WithLogger(logger),
+				WithRedactedKeys(tc.args.redactedKeys...),
+			)
+			_ = tc.args.call(context.Background(), c)
+
+			for _, entry := range logger.entries {
+				if url, ok := entry.Args["url"].(string); ok && !strings.HasPrefix(url, server.URL) {
+					t.Errorf("%s: unexpected url %s", tc.reason, url)
+				}
+				delete(entry.Args, "url")
+				delete(entry.Args, "duration")
+			}
+			if diff := cmp.Diff(tc.want.entries, logger.entries, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("%s: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
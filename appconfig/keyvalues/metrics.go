@@ -0,0 +1,108 @@
+package keyvalues
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// MetricsRecorder receives the measures of the Client requests, labeled
+// with the name of their operation, e.g. appconfig.ListKeyValues. It can
+// feed Prometheus or OpenTelemetry instruments, see the README.
+type MetricsRecorder interface {
+	// RecordRequest records a request attempt.
+	RecordRequest(RequestMetrics)
+
+	// RecordBytesReceived records the size of a response body, once it
+	// has been read.
+	RecordBytesReceived(operation string, bytes int64)
+
+	// RecordPage records a result page of a list operation.
+	RecordPage(operation string)
+}
+
+// RequestMetrics are the measures of a request attempt. The StatusCode is
+// 0 if no response was received, in which case Err is set.
+type RequestMetrics struct {
+	Operation  string
+	Method     string
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+}
+
+// Failed reports whether the request failed, either with an error status
+// or without response.
+func (m RequestMetrics) Failed() bool {
+	return m.Err != nil || m.StatusCode >= 400
+}
+
+// Throttled reports whether the request was throttled (429).
+func (m RequestMetrics) Throttled() bool {
+	return m.StatusCode == http.StatusTooManyRequests
+}
+
+// metricsPolicy is the pipeline Policy recording the measures of each
+// request attempt, if a MetricsRecorder is set.
+func (client *ClientImpl) metricsPolicy(req *http.Request, next Next) (*http.Response, error) {
+	if client.Metrics == nil {
+		return next(req)
+	}
+
+	name := operationName(req.Context())
+	start := time.Now()
+	resp, err := next(req)
+
+	m := RequestMetrics{
+		Operation: name,
+		Method:    req.Method,
+		Duration:  time.Since(start),
+		Err:       err,
+	}
+	if resp != nil {
+		m.StatusCode = resp.StatusCode
+		resp.Body = &countingBody{ReadCloser: resp.Body, record: func(n int64) {
+			client.Metrics.RecordBytesReceived(name, n)
+		}}
+	}
+	client.Metrics.RecordRequest(m)
+	return resp, err
+}
+
+// recordPage records a result page of the operation carried by ctx.
+func (client *ClientImpl) recordPage(ctx context.Context) {
+	if client.Metrics != nil {
+		client.Metrics.RecordPage(operationName(ctx))
+	}
+}
+
+func operationName(ctx context.Context) string {
+	if op, ok := operationFrom(ctx); ok {
+		return op.name
+	}
+	return ""
+}
+
+// countingBody counts the bytes read from a response body, recording them
+// when it is closed.
+type countingBody struct {
+	io.ReadCloser
+	n      int64
+	record func(int64)
+	closed bool
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	if !b.closed {
+		b.closed = true
+		b.record(b.n)
+	}
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,140 @@
+package keyvalues
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+type fakeMetrics struct {
+	requests []RequestMetrics
+	bytes    map[string]int64
+	pages    map[string]int
+}
+
+func (m *fakeMetrics) RecordRequest(r RequestMetrics) { m.requests = append(m.requests, r) }
+func (m *fakeMetrics) RecordBytesReceived(operation string, n int64) {
+	m.bytes[operation] += n
+}
+func (m *fakeMetrics) RecordPage(operation string) { m.pages[operation]++ }
+
+func TestMetrics(t *testing.T) {
+	type args struct {
+		call func(ctx context.Context, c Client) error
+	}
+	type want struct {
+		requests []RequestMetrics
+		bytes    map[string]int64
+		pages    map[string]int
+	}
+
+	page := func(next string) string {
+		return fmt.Sprintf(`{"items":[{"key":"%s"}]}`, next)
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		args    args
+		want    want
+	}{
+		"ListKeyValues": {
+			reason: "Should record each page request of the operation",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("after") == "" {
+					w.Header().Set("Link", `</kv?after=a&api-version=1.0>; rel="next"`)
+				}
+				fmt.Fprint(w, page("a"))
+			},
+			args: args{call: func(ctx context.Context, c Client) error {
+				_, err := c.ListKeyValuesWithContext(ctx, ListKeyValuesArgs{})
+				return err
+			}},
+			want: want{
+				requests: []RequestMetrics{
+					{Operation: "appconfig.ListKeyValues", Method: http.MethodGet, StatusCode: http.StatusOK},
+					{Operation: "appconfig.ListKeyValues", Method: http.MethodGet, StatusCode: http.StatusOK},
+				},
+				bytes: map[string]int64{"appconfig.ListKeyValues": int64(2 * len(page("a")))},
+				pages: map[string]int{"appconfig.ListKeyValues": 2},
+			},
+		},
+		"Pager": {
+			reason: "Should record the pages of a pager used on its own",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, page("a"))
+			},
+			args: args{call: func(ctx context.Context, c Client) error {
+				pager := c.NewListRevisionsPager(ListRevisionsArgs{})
+				for pager.Next(ctx) {
+				}
+				return pager.Err()
+			}},
+			want: want{
+				requests: []RequestMetrics{
+					{Operation: "appconfig.ListRevisions", Method: http.MethodGet, StatusCode: http.StatusOK},
+				},
+				bytes: map[string]int64{"appconfig.ListRevisions": int64(len(page("a")))},
+				pages: map[string]int{"appconfig.ListRevisions": 1},
+			},
+		},
+		"Throttled": {
+			reason: "Should record the throttled attempts",
+			handler: func() http.HandlerFunc {
+				requests := 0
+				return func(w http.ResponseWriter, r *http.Request) {
+					requests++
+					if requests == 1 {
+						w.WriteHeader(http.StatusTooManyRequests)
+						return
+					}
+					w.WriteHeader(http.StatusNoContent)
+				}
+			}(),
+			args: args{call: func(ctx context.Context, c Client) error {
+				return c.DeleteKeyValueWithContext(ctx, fakeKey, fakeLabel)
+			}},
+			want: want{
+				requests: []RequestMetrics{
+					{Operation: "appconfig.DeleteKeyValue", Method: http.MethodDelete, StatusCode: http.StatusTooManyRequests},
+					{Operation: "appconfig.DeleteKeyValue", Method: http.MethodDelete, StatusCode: http.StatusNoContent},
+				},
+				bytes: map[string]int64{"appconfig.DeleteKeyValue": 0},
+				pages: map[string]int{},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+
+			metrics := &fakeMetrics{bytes: map[string]int64{}, pages: map[string]int{}}
+			c := NewClient(server.URL, autorest.NullAuthorizer{},
+				WithMetrics(metrics),
+				WithRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}),
+			)
+			if err := tc.args.call(context.Background(), c); err != nil {
+				t.Fatalf("%s: unexpected error: %v", tc.reason, err)
+			}
+
+			if diff := cmp.Diff(tc.want.requests, metrics.requests, cmpopts.IgnoreFields(RequestMetrics{}, "Duration")); diff != "" {
+				t.Errorf("%s: -want requests, +got requests:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.bytes, metrics.bytes); diff != "" {
+				t.Errorf("%s: -want bytes, +got bytes:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.pages, metrics.pages); diff != "" {
+				t.Errorf("%s: -want pages, +got pages:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -1,5 +1,23 @@
 package keyvalues
 
+import "time"
+
+// Field is the name of a KeyValue field, used to select which fields are
+// returned by the App Configuration API.
+type Field string
+
+// KeyValue fields that can be selected.
+const (
+	FieldKey          Field = "key"
+	FieldLabel        Field = "label"
+	FieldContentType  Field = "content_type"
+	FieldValue        Field = "value"
+	FieldLastModified Field = "last_modified"
+	FieldLocked       Field = "locked"
+	FieldTags         Field = "tags"
+	FieldEtag         Field = "etag"
+)
+
 // KeyValue represents a Key Value response
 type KeyValue struct {
 	Etag         *string            `json:"etag,omitempty"`
@@ -14,9 +32,65 @@ type KeyValue struct {
 
 // ListKeyValuesArgs represents the argument for the
 // ListKeyValues SDK method.
+//
+// Key and Label are raw App Configuration filters, sent as is. Keys and
+// Labels are joined with them into a comma-separated filter matching any
+// of the values, with the reserved characters escaped. A trailing "*" is
+// kept as a wildcard and NullLabel matches the KeyValues without a label.
+// Example:
+// Keys: []string{"app:*"}, Labels: []string{"prod", NullLabel}
+//
+// Tags only lists the KeyValues having all the given tag names and values.
+// Snapshot lists the KeyValues of the named Snapshot instead. Combined with
+// the key, label or tags filters, the listing fails with
+// ErrSnapshotFilters.
+// Select restricts the returned KeyValue fields, leaving the others nil.
+// AsOf lists the KeyValues as they were at the given time, sent as the
+// Accept-Datetime header.
+// NextLink resumes a listing from the KeyValues.NextLink of one of its
+// pages. The link carries the key, label, tags and Snapshot filters of
+// the listing, so these are ignored, while Select and AsOf must be set
+// again.
 type ListKeyValuesArgs struct {
-	Key   string
-	Label string
+	Key      string
+	Label    string
+	Keys     []string
+	Labels   []string
+	Tags     map[string]string
+	Snapshot string
+	Select   []Field
+	AsOf     time.Time
+	NextLink string
+}
+
+// GetKeyValueArgs represents the argument for the
+// GetKeyValueWithArgs SDK method.
+//
+// IfNoneMatch is a known KeyValue Etag. If the KeyValue did not change
+// since, the request returns ErrNotModified instead of the KeyValue.
+//
+// Select restricts the returned KeyValue fields, leaving the others nil.
+// AsOf gets the KeyValue as it was at the given time, sent as the
+// Accept-Datetime header.
+type GetKeyValueArgs struct {
+	Key         string
+	Label       string
+	IfNoneMatch string
+	Select      []Field
+	AsOf        time.Time
+}
+
+// ListRevisionsArgs represents the argument for the
+// ListRevisions SDK method.
+//
+// Select restricts the returned KeyValue fields, leaving the others nil.
+// AsOf lists the revisions as they were at the given time, sent as the
+// Accept-Datetime header.
+type ListRevisionsArgs struct {
+	Key    string
+	Label  string
+	Select []Field
+	AsOf   time.Time
 }
 
 // CreateOrUpdateKeyValueArgs represents the argument for the
@@ -29,6 +103,11 @@ type ListKeyValuesArgs struct {
 // is an Azure Key Vault reference.
 // Example:
 // https://my-vault.vault.azure.net/secrets/mysecret
+//
+// IfMatch only applies the change if the current KeyValue Etag matches it
+// ("*" matches any existing KeyValue). IfNoneMatch set to "*" only creates
+// the KeyValue if it does not exist yet. A mismatch returns an error for
+// which IsPreconditionFailed is true.
 type CreateOrUpdateKeyValueArgs struct {
 	Key         string            `json:"key,omitempty"`
 	Label       string            `json:"label,omitempty"`
@@ -36,23 +115,194 @@ type CreateOrUpdateKeyValueArgs struct {
 	Value       string            `json:"value,omitempty"`
 	Tags        map[string]string `json:"tags,omitempty"`
 	IsSecret    bool              `json:"isSecret,omitempty"`
+	IfMatch     string            `json:"-"`
+	IfNoneMatch string            `json:"-"`
+}
+
+// DeleteKeyValueArgs represents the argument for the
+// DeleteKeyValueWithArgs SDK method.
+//
+// IfMatch only deletes the KeyValue if its current Etag matches it. A
+// mismatch returns an error for which IsPreconditionFailed is true.
+type DeleteKeyValueArgs struct {
+	Key     string
+	Label   string
+	IfMatch string
 }
 
 // NewClientAzureADArgs represents the argument for the
 // NewClientAzureAD SDK method.
 //
+// Cloud selects the Azure AD authority and the token audience, e.g.
+// AzureChinaCloud. An explicit AADEndpoint takes precedence over it.
+//
 // Required: ClientID, ClientSecret, TenantID, ResourceEndpoint
-// Optional: AADEndpoint
+// Optional: AADEndpoint, Cloud
 type NewClientAzureADArgs struct {
 	ClientID         string
 	ClientSecret     string
 	TenantID         string
 	AADEndpoint      string
 	ResourceEndpoint string
+	Cloud            Cloud
+}
+
+// NewClientWorkloadIdentityArgs represents the argument for the
+// NewClientWorkloadIdentity SDK method.
+//
+// TokenFilePath is the federated token file, usually provided by the
+// AZURE_FEDERATED_TOKEN_FILE environment variable.
+//
+// Cloud selects the Azure AD authority and the token audience, e.g.
+// AzureChinaCloud. An explicit AADEndpoint takes precedence over it.
+//
+// Required: ClientID, TenantID, TokenFilePath, ResourceEndpoint
+// Optional: AADEndpoint, Cloud
+type NewClientWorkloadIdentityArgs struct {
+	ClientID         string
+	TenantID         string
+	TokenFilePath    string
+	AADEndpoint      string
+	ResourceEndpoint string
+	Cloud            Cloud
+}
+
+// NewClientManagedIdentityArgs represents the argument for the
+// NewClientManagedIdentity SDK method.
+//
+// ClientID selects a user-assigned identity, the system-assigned identity
+// is used if it is empty. Cloud selects the token audience, e.g.
+// AzureChinaCloud.
+//
+// Required: ResourceEndpoint
+// Optional: ClientID, Cloud
+type NewClientManagedIdentityArgs struct {
+	ClientID         string
+	ResourceEndpoint string
+	Cloud            Cloud
+}
+
+// NewClientFromEnvironmentArgs represents the argument for the
+// NewClientFromEnvironment SDK method.
+//
+// Cloud selects the Azure AD authority and the token audience of every
+// credential but the connection string, e.g. AzureChinaCloud.
+//
+// Required: ResourceEndpoint
+// Optional: Cloud
+type NewClientFromEnvironmentArgs struct {
+	ResourceEndpoint string
+	Cloud            Cloud
 }
 
 // KeyValues represents the response of the
 // ListKeyValues SDK method.
+//
+// NextLink holds the continuation link of a single result page, taken from
+// the response Link header. It is empty once the listing is exhausted, so
+// always for ListKeyValues, which fetches every page. Pass it as
+// ListKeyValuesArgs.NextLink to resume the listing later.
 type KeyValues struct {
-	Items []KeyValue `json:"items"`
+	Items    []KeyValue `json:"items"`
+	NextLink string     `json:"-"`
+}
+
+// Key represents a distinct key returned by the
+// ListKeys SDK method.
+type Key struct {
+	Name *string `json:"name,omitempty"`
+}
+
+// Keys represents the response of the
+// ListKeys SDK method.
+type Keys struct {
+	Items []Key `json:"items"`
+}
+
+// Label represents a distinct label returned by the
+// ListLabels SDK method.
+type Label struct {
+	Name *string `json:"name,omitempty"`
+}
+
+// Labels represents the response of the
+// ListLabels SDK method.
+type Labels struct {
+	Items []Label `json:"items"`
+}
+
+// Snapshot composition types, defining how the KeyValues matched by the
+// filters are composed into a Snapshot.
+const (
+	// CompositionTypeKey keeps a single KeyValue per key, the one of the
+	// last matching filter.
+	CompositionTypeKey = "key"
+	// CompositionTypeKeyLabel keeps a KeyValue per key and label pair.
+	CompositionTypeKeyLabel = "key_label"
+)
+
+// Snapshot statuses.
+const (
+	SnapshotStatusProvisioning = "provisioning"
+	SnapshotStatusReady        = "ready"
+	SnapshotStatusArchived     = "archived"
+	SnapshotStatusFailed       = "failed"
+)
+
+// Snapshot represents a Snapshot response
+type Snapshot struct {
+	Etag            *string            `json:"etag,omitempty"`
+	Name            *string            `json:"name,omitempty"`
+	Status          *string            `json:"status,omitempty"`
+	Filters         []SnapshotFilter   `json:"filters,omitempty"`
+	CompositionType *string            `json:"composition_type,omitempty"`
+	Created         *string            `json:"created,omitempty"`
+	Expires         *string            `json:"expires,omitempty"`
+	RetentionPeriod *int64             `json:"retention_period,omitempty"`
+	Size            *int64             `json:"size,omitempty"`
+	ItemsCount      *int64             `json:"items_count,omitempty"`
+	Tags            *map[string]string `json:"tags,omitempty"`
+}
+
+// SnapshotFilter selects the KeyValues composing a Snapshot.
+//
+// Required: Key
+// Optional: Label; Tags (e.g. "owner=payments")
+type SnapshotFilter struct {
+	Key   string   `json:"key"`
+	Label string   `json:"label,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+// Snapshots represents the response of the
+// ListSnapshots SDK method.
+type Snapshots struct {
+	Items []Snapshot `json:"items"`
+}
+
+// CreateSnapshotArgs represents the argument for the
+// CreateSnapshot SDK method.
+//
+// RetentionPeriod is the number of seconds an archived Snapshot is kept
+// before expiring. PollInterval is the wait between the checks of the
+// creation operation, one second if not specified.
+//
+// Required: Name; Filters
+// Optional: CompositionType; RetentionPeriod; Tags; PollInterval
+type CreateSnapshotArgs struct {
+	Name            string            `json:"-"`
+	Filters         []SnapshotFilter  `json:"filters"`
+	CompositionType string            `json:"composition_type,omitempty"`
+	RetentionPeriod int64             `json:"retention_period,omitempty"`
+	Tags            map[string]string `json:"tags,omitempty"`
+	PollInterval    time.Duration     `json:"-"`
+}
+
+// ListSnapshotsArgs represents the argument for the
+// ListSnapshots SDK method.
+//
+// Optional: Name (if not specified, it implies any Snapshot); Status
+type ListSnapshotsArgs struct {
+	Name   string
+	Status []string
 }
@@ -0,0 +1,77 @@
+package keyvalues
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// ListKeys returns the distinct App Configuration keys, filtered by the
+// provided name filter (if empty, it implies any key). All result pages
+// are fetched by following the continuation links.
+func (client *ClientImpl) ListKeys(ctx context.Context, nameFilter string) (result Keys, err error) {
+	ctx, op := client.startOperation(ctx, "appconfig.ListKeys", AttributeKey, nameFilter)
+	defer func() {
+		op.set(AttributeItemCount, len(result.Items))
+		op.end(err)
+	}()
+
+	req, err := client.createNamesRequest(ctx, "/keys", nameFilter)
+	if err != nil {
+		return Keys{}, err
+	}
+
+	err = client.collectPages(ctx, req, func(response *http.Response) error {
+		var page Keys
+		if err := getJSON(response, &page); err != nil {
+			return err
+		}
+		result.Items = append(result.Items, page.Items...)
+		return nil
+	})
+	if err != nil {
+		return Keys{}, err
+	}
+	return result, nil
+}
+
+// ListLabels returns the distinct App Configuration labels, filtered by
+// the provided name filter (if empty, it implies any label). All result
+// pages are fetched by following the continuation links.
+func (client *ClientImpl) ListLabels(ctx context.Context, nameFilter string) (result Labels, err error) {
+	ctx, op := client.startOperation(ctx, "appconfig.ListLabels", AttributeLabel, nameFilter)
+	defer func() {
+		op.set(AttributeItemCount, len(result.Items))
+		op.end(err)
+	}()
+
+	req, err := client.createNamesRequest(ctx, "/labels", nameFilter)
+	if err != nil {
+		return Labels{}, err
+	}
+
+	err = client.collectPages(ctx, req, func(response *http.Response) error {
+		var page Labels
+		if err := getJSON(response, &page); err != nil {
+			return err
+		}
+		result.Items = append(result.Items, page.Items...)
+		return nil
+	})
+	if err != nil {
+		return Labels{}, err
+	}
+	return result, nil
+}
+
+func (client *ClientImpl) createNamesRequest(ctx context.Context, path, nameFilter string) (*http.Request, error) {
+	queryParameters := map[string]interface{}{
+		"api-version": apiVersion,
+	}
+	if nameFilter != "" {
+		queryParameters["name"] = autorest.Encode("query", nameFilter)
+	}
+
+	return client.createQueryRequest(ctx, path, queryParameters, autorest.AsGet())
+}
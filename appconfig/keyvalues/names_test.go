@@ -0,0 +1,202 @@
+package keyvalues
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/stone-payments/appconfig-go-sdk/appconfig/test"
+)
+
+func TestListKeys(t *testing.T) {
+	type args struct {
+		nameFilter string
+	}
+	type want struct {
+		keys Keys
+		err  error
+	}
+
+	otherKey := "otherKey"
+
+	cases := map[string]struct {
+		reason  string
+		handler http.Handler
+		args    args
+		want    want
+	}{
+		"ListKeysSucessfully": {
+			reason: "Should return the keys of every page matching the filter",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.HasPrefix(r.URL.Path, "/keys") {
+					if r.URL.Query().Get("name") != "fake*" {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					response := Keys{Items: []Key{{Name: &otherKey}}}
+					if r.URL.Query().Get("after") == "" {
+						w.Header().Set("Link", `</keys?after=fake&name=fake*&api-version=1.0>; rel="next"`)
+						response = Keys{Items: []Key{{Name: &fakeKey}}}
+					}
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&response)
+				}
+			}),
+			args: args{
+				nameFilter: "fake*",
+			},
+			want: want{
+				keys: Keys{Items: []Key{{Name: &fakeKey}, {Name: &otherKey}}},
+				err:  nil,
+			},
+		},
+		"ListKeysEncodedFilter": {
+			reason: "Should send the name filter with its plus and percent signs",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.HasPrefix(r.URL.Path, "/keys") {
+					if r.URL.Query().Get("name") != "a+b 100%*" {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(Keys{Items: []Key{{Name: &fakeKey}}})
+				}
+			}),
+			args: args{
+				nameFilter: "a+b 100%*",
+			},
+			want: want{
+				keys: Keys{Items: []Key{{Name: &fakeKey}}},
+				err:  nil,
+			},
+		},
+		"ListKeysInternalError": {
+			reason: "Should return an error if the list returns Status Code greater than 399",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.Contains(r.URL.String(), "keys") {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}),
+			want: want{
+				keys: Keys{},
+				err:  errInternal,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+			args := NewClientAzureADArgs{ClientID: fakeIDs, TenantID: fakeIDs, ClientSecret: fakeIDs, ResourceEndpoint: server.URL, AADEndpoint: server.URL}
+			c, _ := NewClientAzureAD(args)
+
+			got, err := c.ListKeys(context.Background(), tc.args.nameFilter)
+
+			if diff := cmp.Diff(tc.want.keys, got); diff != "" {
+				t.Errorf("ListKeys(...): -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("ListKeys(...): -want error, +got error:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestListLabels(t *testing.T) {
+	type args struct {
+		nameFilter string
+	}
+	type want struct {
+		labels Labels
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler http.Handler
+		args    args
+		want    want
+	}{
+		"ListLabelsSucessfully": {
+			reason: "Should return the labels matching the filter",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.HasPrefix(r.URL.Path, "/labels") {
+					if _, ok := r.URL.Query()["name"]; ok {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(Labels{Items: []Label{{Name: &fakeLabel}, {}}})
+				}
+			}),
+			args: args{
+				nameFilter: "",
+			},
+			want: want{
+				labels: Labels{Items: []Label{{Name: &fakeLabel}, {}}},
+				err:    nil,
+			},
+		},
+		"ListLabelsInternalError": {
+			reason: "Should return an error if the list returns Status Code greater than 399",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.Contains(r.URL.String(), "labels") {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}),
+			want: want{
+				labels: Labels{},
+				err:    errInternal,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+			args := NewClientAzureADArgs{ClientID: fakeIDs, TenantID: fakeIDs, ClientSecret: fakeIDs, ResourceEndpoint: server.URL, AADEndpoint: server.URL}
+			c, _ := NewClientAzureAD(args)
+
+			got, err := c.ListLabels(context.Background(), tc.args.nameFilter)
+
+			if diff := cmp.Diff(tc.want.labels, got); diff != "" {
+				t.Errorf("ListLabels(...): -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("ListLabels(...): -want error, +got error:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package keyvalues
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// ClientOption configures a Client. The options are accepted by every
+// Client constructor.
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	httpClient       *http.Client
+	transport        http.RoundTripper
+	timeout          time.Duration
+	userAgent        string
+	retryPolicy      RetryPolicy
+	logger           Logger
+	redactedKeys     []*regexp.Regexp
+	apiVersion       string
+	policies         []Policy
+	perRetryPolicies []Policy
+	tracer           Tracer
+	metrics          MetricsRecorder
+}
+
+// WithHTTPClient sets the http.Client used to send the requests.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(o *clientOptions) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithTransport sets the http.RoundTripper used to send the requests.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(o *clientOptions) {
+		o.transport = transport
+	}
+}
+
+// WithTimeout sets the time limit of each request attempt, including
+// reading the response body.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithUserAgent appends a suffix to the User-Agent header of the requests.
+func WithUserAgent(suffix string) ClientOption {
+	return func(o *clientOptions) {
+		o.userAgent = suffix
+	}
+}
+
+// WithRetryPolicy sets the RetryPolicy of the idempotent requests.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(o *clientOptions) {
+		o.retryPolicy = policy
+	}
+}
+
+// WithLogger sets the Logger of the requests.
+func WithLogger(logger Logger) ClientOption {
+	return func(o *clientOptions) {
+		o.logger = logger
+	}
+}
+
+// WithRedactedKeys adds patterns matching the keys whose values are
+// redacted from the logs, in addition to the Key Vault references.
+func WithRedactedKeys(patterns ...*regexp.Regexp) ClientOption {
+	return func(o *clientOptions) {
+		o.redactedKeys = append(o.redactedKeys, patterns...)
+	}
+}
+
+// WithAPIVersion sets the api-version sent on every request, overriding
+// the version chosen for each operation.
+func WithAPIVersion(version string) ClientOption {
+	return func(o *clientOptions) {
+		o.apiVersion = version
+	}
+}
+
+// WithMiddleware adds autorest.SendDecorators wrapping each request
+// attempt, in the given order. They run as PerRetryPolicies, see
+// WithPerRetryPolicies.
+func WithMiddleware(middleware ...autorest.SendDecorator) ClientOption {
+	return func(o *clientOptions) {
+		for _, decorator := range middleware {
+			o.perRetryPolicies = append(o.perRetryPolicies, decoratorPolicy(decorator))
+		}
+	}
+}
+
+// WithPolicies adds Policies to the pipeline wrapping each request, in the
+// given order. They run once per request, before the retries.
+func WithPolicies(policies ...Policy) ClientOption {
+	return func(o *clientOptions) {
+		o.policies = append(o.policies, policies...)
+	}
+}
+
+// WithPerRetryPolicies adds Policies to the pipeline wrapping each request
+// attempt, in the given order. They run for each attempt, after the
+// retries.
+func WithPerRetryPolicies(policies ...Policy) ClientOption {
+	return func(o *clientOptions) {
+		o.perRetryPolicies = append(o.perRetryPolicies, policies...)
+	}
+}
+
+// WithTracer sets the Tracer of the operations.
+func WithTracer(tracer Tracer) ClientOption {
+	return func(o *clientOptions) {
+		o.tracer = tracer
+	}
+}
+
+// WithMetrics sets the MetricsRecorder of the requests.
+func WithMetrics(recorder MetricsRecorder) ClientOption {
+	return func(o *clientOptions) {
+		o.metrics = recorder
+	}
+}
+
+func newClientOptions(opts []ClientOption) clientOptions {
+	o := clientOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// sender returns the http.Client built from the options, or nil to use the
+// autorest default one.
+func (o clientOptions) sender() *http.Client {
+	if o.httpClient == nil && o.transport == nil && o.timeout == 0 {
+		return nil
+	}
+
+	sender := &http.Client{}
+	if o.httpClient != nil {
+		c := *o.httpClient
+		sender = &c
+	}
+	if o.transport != nil {
+		sender.Transport = o.transport
+	}
+	if o.timeout > 0 {
+		sender.Timeout = o.timeout
+	}
+	return sender
+}
@@ -0,0 +1,168 @@
+package keyvalues
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/google/go-cmp/cmp"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+type logEntry struct {
+	Level   string
+	Message string
+	Args    map[string]interface{}
+}
+
+type fakeLogger struct {
+	entries []logEntry
+}
+
+func (l *fakeLogger) log(level, msg string, args []interface{}) {
+	entry := logEntry{Level: level, Message: msg, Args: map[string]interface{}{}}
+	for i := 0; i+1 < len(args); i += 2 {
+		entry.Args[args[i].(string)] = args[i+1]
+	}
+	l.entries = append(l.entries, entry)
+}
+
+func (l *fakeLogger) Debug(msg string, args ...interface{}) { l.log("DEBUG", msg, args) }
+func (l *fakeLogger) Error(msg string, args ...interface{}) { l.log("ERROR", msg, args) }
+
+func (l *fakeLogger) messages() []string {
+	messages := make([]string, len(l.entries))
+	for i, entry := range l.entries {
+		messages[i] = entry.Message
+	}
+	return messages
+}
+
+func TestClientOptions(t *testing.T) {
+	type args struct {
+		opts func(server *httptest.Server) []ClientOption
+	}
+	type want struct {
+		request func(r *http.Request) bool
+		failed  bool
+	}
+
+	var transportCalls int
+	logger := &fakeLogger{}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"UserAgent": {
+			reason: "Should append the suffix to the User-Agent header",
+			args: args{opts: func(*httptest.Server) []ClientOption {
+				return []ClientOption{WithUserAgent("my-service/1.0")}
+			}},
+			want: want{request: func(r *http.Request) bool {
+				return strings.HasSuffix(r.UserAgent(), " my-service/1.0")
+			}},
+		},
+		"APIVersion": {
+			reason: "Should override the api-version of the request",
+			args: args{opts: func(*httptest.Server) []ClientOption {
+				return []ClientOption{WithAPIVersion("2022-11-01-preview")}
+			}},
+			want: want{request: func(r *http.Request) bool {
+				return r.URL.Query().Get("api-version") == "2022-11-01-preview"
+			}},
+		},
+		"Transport": {
+			reason: "Should send the request through the custom transport",
+			args: args{opts: func(*httptest.Server) []ClientOption {
+				return []ClientOption{WithTransport(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					transportCalls++
+					return http.DefaultTransport.RoundTrip(r)
+				}))}
+			}},
+			want: want{request: func(r *http.Request) bool {
+				return transportCalls == 1
+			}},
+		},
+		"HTTPClient": {
+			reason: "Should send the request through the custom http.Client",
+			args: args{opts: func(server *httptest.Server) []ClientOption {
+				return []ClientOption{WithHTTPClient(server.Client())}
+			}},
+			want: want{request: func(r *http.Request) bool { return true }},
+		},
+		"Middleware": {
+			reason: "Should wrap the request with the middleware",
+			args: args{opts: func(*httptest.Server) []ClientOption {
+				return []ClientOption{WithMiddleware(func(s autorest.Sender) autorest.Sender {
+					return autorest.SenderFunc(func(r *http.Request) (*http.Response, error) {
+						r.Header.Set("x-ms-correlation-request-id", "my-correlation")
+						return s.Do(r)
+					})
+				})}
+			}},
+			want: want{request: func(r *http.Request) bool {
+				return r.Header.Get("x-ms-correlation-request-id") == "my-correlation"
+			}},
+		},
+		"Logger": {
+			reason: "Should log the request",
+			args: args{opts: func(*httptest.Server) []ClientOption {
+				return []ClientOption{WithLogger(logger)}
+			}},
+			want: want{request: func(r *http.Request) bool { return true }},
+		},
+		"Timeout": {
+			reason: "Should fail the requests slower than the timeout",
+			args: args{opts: func(*httptest.Server) []ClientOption {
+				return []ClientOption{WithTimeout(time.Millisecond)}
+			}},
+			want: want{failed: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got *http.Request
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.want.failed {
+					time.Sleep(50 * time.Millisecond)
+				}
+				got = r
+				_ = json.NewEncoder(w).Encode(KeyValue{Key: &fakeKey})
+			}))
+			defer server.Close()
+
+			c := NewClient(server.URL, autorest.NullAuthorizer{}, tc.args.opts(server)...)
+			_, err := c.GetKeyValue(fakeKey, fakeLabel)
+
+			if diff := cmp.Diff(tc.want.failed, err != nil); diff != "" {
+				t.Fatalf("%s: -want failed, +got failed:\n%s\nerror: %v", tc.reason, diff, err)
+			}
+			if tc.want.request != nil && !tc.want.request(got) {
+				t.Errorf("%s: unexpected request %s %s %v", tc.reason, got.Method, got.URL, got.Header)
+			}
+		})
+	}
+
+	if diff := cmp.Diff([]string{"appconfig request", "appconfig response body"}, logger.messages()); diff != "" {
+		t.Errorf("Logger: -want, +got:\n%s", diff)
+	}
+}
+
+func TestClientOptionsRetryPolicy(t *testing.T) {
+	c := NewClient(fakeIDs, autorest.NullAuthorizer{}, WithRetryPolicy(DefaultRetryPolicy))
+	if diff := cmp.Diff(DefaultRetryPolicy, c.(*ClientImpl).RetryPolicy); diff != "" {
+		t.Errorf("WithRetryPolicy(...): -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,109 @@
+package keyvalues
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// KeyValuesPager iterates over the pages of a KeyValues listing. Pages are
+// fetched lazily, one request per call to Next, so callers can walk large
+// stores without holding every KeyValue in memory and stop early at will.
+//
+// Example:
+//
+//	pager := client.NewListKeyValuesPager(args)
+//	for pager.Next(ctx) {
+//		for _, kv := range pager.Page().Items {
+//			...
+//		}
+//	}
+//	if err := pager.Err(); err != nil {
+//		...
+//	}
+type KeyValuesPager interface {
+	// Next fetches the next page. It returns false when there are no more
+	// pages or when an error occurred, which is then reported by Err.
+	Next(ctx context.Context) bool
+
+	// Page returns the page fetched by the last call to Next.
+	Page() KeyValues
+
+	// Err returns the error, if any, that stopped the iteration.
+	Err() error
+}
+
+type keyValuesPager struct {
+	client       *ClientImpl
+	operation    string
+	attributes   []string
+	firstRequest func(context.Context) (*http.Request, error)
+	decorators   []autorest.PrepareDecorator
+	page         KeyValues
+	started      bool
+	err          error
+}
+
+// newKeyValuesPager creates a pager of the named operation starting with
+// the request built by firstRequest. The attributes are set on the Span of
+// each page, see startOperation. The decorators are applied to the
+// following page requests, as the continuation links do not carry request
+// headers.
+func newKeyValuesPager(client *ClientImpl, operation string, attributes []string, firstRequest func(context.Context) (*http.Request, error), decorators ...autorest.PrepareDecorator) *keyValuesPager {
+	return &keyValuesPager{
+		client:       client,
+		operation:    operation,
+		attributes:   attributes,
+		firstRequest: firstRequest,
+		decorators:   decorators,
+	}
+}
+
+func (p *keyValuesPager) Next(ctx context.Context) (ok bool) {
+	if p.err != nil || (p.started && p.page.NextLink == "") {
+		return false
+	}
+	// A pager used on its own traces each page as an operation, otherwise
+	// the pages belong to the listing operation carried by ctx.
+	if _, listing := operationFrom(ctx); !listing {
+		var op *operation
+		ctx, op = p.client.startOperation(ctx, p.operation, p.attributes...)
+		defer func() {
+			if ok {
+				op.set(AttributeItemCount, len(p.page.Items))
+			}
+			op.end(p.err)
+		}()
+	}
+
+	var req *http.Request
+	var err error
+	if !p.started {
+		req, err = p.firstRequest(ctx)
+	} else {
+		req, err = p.client.createNextRequest(ctx, p.page.NextLink, p.decorators...)
+	}
+	if err != nil {
+		p.err = err
+		return false
+	}
+
+	page, err := p.client.listKeyValuesPage(req)
+	if err != nil {
+		p.err = err
+		return false
+	}
+	p.client.recordPage(ctx)
+	p.started = true
+	p.page = page
+	return true
+}
+
+func (p *keyValuesPager) Page() KeyValues {
+	return p.page
+}
+
+func (p *keyValuesPager) Err() error {
+	return p.err
+}
@@ -0,0 +1,136 @@
+package keyvalues
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/stone-payments/appconfig-go-sdk/appconfig/test"
+)
+
+func TestNewListKeyValuesPager(t *testing.T) {
+	type args struct {
+		ListKeyValuesArgs
+		maxPages int
+	}
+	type want struct {
+		pages []KeyValues
+		err   error
+	}
+
+	pagedHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body.Close()
+		if strings.Contains(r.URL.String(), "oauth") {
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(&token{})
+		}
+		if strings.Contains(r.URL.String(), "kv") {
+			response := KeyValues{Items: []KeyValue{{Key: &fakeKey}}}
+			if r.URL.Query().Get("after") == "" {
+				w.Header().Set("Link", `</kv?after=fake&api-version=1.0>; rel="next"`)
+				response = KeyValues{Items: []KeyValue{{}, {}}}
+			}
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(&response)
+		}
+	})
+
+	cases := map[string]struct {
+		reason  string
+		handler http.Handler
+		args    args
+		want    want
+	}{
+		"IterateAllPages": {
+			reason:  "Should fetch every page until the Link header is exhausted",
+			handler: pagedHandler,
+			args: args{
+				ListKeyValuesArgs: ListKeyValuesArgs{},
+			},
+			want: want{
+				pages: []KeyValues{
+					{Items: []KeyValue{{}, {}}, NextLink: "/kv?after=fake&api-version=1.0"},
+					{Items: []KeyValue{{Key: &fakeKey}}},
+				},
+				err: nil,
+			},
+		},
+		"StopEarly": {
+			reason:  "Should not fetch pages that are never requested",
+			handler: pagedHandler,
+			args: args{
+				ListKeyValuesArgs: ListKeyValuesArgs{},
+				maxPages:          1,
+			},
+			want: want{
+				pages: []KeyValues{
+					{Items: []KeyValue{{}, {}}, NextLink: "/kv?after=fake&api-version=1.0"},
+				},
+				err: nil,
+			},
+		},
+		"ResumeFromNextLink": {
+			reason:  "Should resume the listing from the NextLink of a previous page",
+			handler: pagedHandler,
+			args: args{
+				ListKeyValuesArgs: ListKeyValuesArgs{NextLink: "/kv?after=fake&api-version=1.0"},
+			},
+			want: want{
+				pages: []KeyValues{
+					{Items: []KeyValue{{Key: &fakeKey}}},
+				},
+				err: nil,
+			},
+		},
+		"PageInternalError": {
+			reason: "Should stop the iteration and report the error if a page fails",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.Contains(r.URL.String(), "kv") {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}),
+			args: args{
+				ListKeyValuesArgs: ListKeyValuesArgs{},
+			},
+			want: want{
+				pages: nil,
+				err:   errInternal,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+			args := NewClientAzureADArgs{ClientID: fakeIDs, TenantID: fakeIDs, ClientSecret: fakeIDs, ResourceEndpoint: server.URL, AADEndpoint: server.URL}
+			c, _ := NewClientAzureAD(args)
+
+			var got []KeyValues
+			pager := c.NewListKeyValuesPager(tc.args.ListKeyValuesArgs)
+			for pager.Next(context.Background()) {
+				got = append(got, pager.Page())
+				if len(got) == tc.args.maxPages {
+					break
+				}
+			}
+
+			if diff := cmp.Diff(tc.want.pages, got); diff != "" {
+				t.Errorf("NewListKeyValuesPager(...): -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.err, pager.Err(), test.EquateErrors()); diff != "" {
+				t.Errorf("NewListKeyValuesPager(...): -want error, +got error:\n%s", diff)
+			}
+		})
+	}
+}
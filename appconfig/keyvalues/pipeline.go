@@ -0,0 +1,64 @@
+package keyvalues
+
+import (
+	"net/http"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// Next sends the request to the rest of the pipeline.
+type Next func(req *http.Request) (*http.Response, error)
+
+// Policy intercepts the requests sent by the Client. A Policy may change
+// the request, send it any number of times through next, or answer it on
+// its own.
+//
+// The Policies set with WithPolicies run once per request, before the
+// retries, e.g. to set a correlation ID. The ones set with
+// WithPerRetryPolicies run for each attempt, after the retries, sync
+// tokens, tracing, metrics and logging, e.g. to sign requests or inject
+// faults that are retried. The middleware set with WithMiddleware runs
+// among them, in the order the options are given:
+//
+//	func correlation(req *http.Request, next keyvalues.Next) (*http.Response, error) {
+//		req.Header.Set("x-ms-correlation-request-id", uuid.NewString())
+//		return next(req)
+//	}
+type Policy func(req *http.Request, next Next) (*http.Response, error)
+
+// pipeline is a chain of Policies, the first one wrapping the others.
+type pipeline []Policy
+
+// pipeline returns the Policies wrapping each request: the Client
+// Policies, then the retries, the sync tokens, the tracing, the metrics,
+// the logging and the PerRetryPolicies of each attempt.
+func (client *ClientImpl) pipeline() pipeline {
+	policies := make(pipeline, 0, len(client.Policies)+len(client.PerRetryPolicies)+5)
+	policies = append(policies, client.Policies...)
+	policies = append(policies,
+		client.RetryPolicy.policy,
+		client.syncTokenPolicy,
+		client.tracingPolicy,
+		client.metricsPolicy,
+		client.loggingPolicy,
+	)
+	return append(policies, client.PerRetryPolicies...)
+}
+
+// send sends the request through the Policies, then the transport.
+func (p pipeline) send(req *http.Request, transport Next) (*http.Response, error) {
+	if len(p) == 0 {
+		return transport(req)
+	}
+	return p[0](req, func(req *http.Request) (*http.Response, error) {
+		return p[1:].send(req, transport)
+	})
+}
+
+// decoratorPolicy returns a Policy sending the requests through the Sender
+// returned by decorator.
+func decoratorPolicy(decorator autorest.SendDecorator) Policy {
+	return func(req *http.Request, next Next) (*http.Response, error) {
+		return decorator(autorest.SenderFunc(next)).Do(req)
+	}
+}
@@ -0,0 +1,221 @@
+package keyvalues
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/stone-payments/appconfig-go-sdk/appconfig/test"
+)
+
+func TestPipeline(t *testing.T) {
+	type want struct {
+		calls    []string
+		requests int
+		kv       KeyValue
+		err      error
+	}
+
+	// recording returns a Policy recording its calls.
+	recording := func(calls *[]string, name string) Policy {
+		return func(req *http.Request, next Next) (*http.Response, error) {
+			*calls = append(*calls, name)
+			return next(req)
+		}
+	}
+
+	cases := map[string]struct {
+		reason   string
+		status   int
+		policies func(calls *[]string) []Policy
+		want     want
+	}{
+		"Order": {
+			reason: "Should run the Policies in order, once per request",
+			status: http.StatusServiceUnavailable,
+			policies: func(calls *[]string) []Policy {
+				return []Policy{recording(calls, "first"), recording(calls, "second")}
+			},
+			want: want{
+				calls:    []string{"first", "second"},
+				requests: 2,
+				kv:       KeyValue{Key: &fakeKey},
+			},
+		},
+		"Headers": {
+			reason: "Should send the headers set by a Policy",
+			status: http.StatusOK,
+			policies: func(calls *[]string) []Policy {
+				return []Policy{func(req *http.Request, next Next) (*http.Response, error) {
+					req.Header.Set("x-ms-client-request-id", fakeIDs)
+					return next(req)
+				}}
+			},
+			want: want{
+				requests: 1,
+				kv:       KeyValue{Key: &fakeKey, Value: &fakeIDs},
+			},
+		},
+		"ShortCircuit": {
+			reason: "Should return the response of a Policy not calling next",
+			status: http.StatusOK,
+			policies: func(calls *[]string) []Policy {
+				return []Policy{func(req *http.Request, next Next) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: http.StatusInternalServerError,
+						Status:     "500 Internal Server Error",
+						Header:     http.Header{},
+						Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+					}, nil
+				}}
+			},
+			want: want{
+				requests: 0,
+				err:      errInternal,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if requests == 1 && tc.status != http.StatusOK {
+					w.WriteHeader(tc.status)
+					return
+				}
+				kv := KeyValue{Key: &fakeKey}
+				if id := r.Header.Get("x-ms-client-request-id"); id != "" {
+					kv.Value = &id
+				}
+				_ = json.NewEncoder(w).Encode(kv)
+			}))
+			defer server.Close()
+
+			var calls []string
+			c := NewClient(server.URL, autorest.NullAuthorizer{},
+				WithPolicies(tc.policies(&calls)...),
+				WithRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}),
+			)
+			got, err := c.GetKeyValue(fakeKey, fakeLabel)
+
+			if diff := cmp.Diff(tc.want.kv, got); diff != "" {
+				t.Errorf("%s: -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("%s: -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.calls, calls); diff != "" {
+				t.Errorf("%s: -want calls, +got calls:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.requests, requests); diff != "" {
+				t.Errorf("%s: -want requests, +got requests:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPerRetryPolicies(t *testing.T) {
+	var attempts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts = append(attempts, r.Header.Get("x-attempt"))
+		_ = json.NewEncoder(w).Encode(KeyValue{Key: &fakeKey})
+	}))
+	defer server.Close()
+
+	calls := 0
+	perRequest := func(req *http.Request, next Next) (*http.Response, error) {
+		calls++
+		return next(req)
+	}
+	attempt := 0
+	chaos := func(req *http.Request, next Next) (*http.Response, error) {
+		attempt++
+		if attempt == 1 {
+			return &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Status:     "503 Service Unavailable",
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+			}, nil
+		}
+		req.Header.Set("x-attempt", strconv.Itoa(attempt))
+		return next(req)
+	}
+
+	c := NewClient(server.URL, autorest.NullAuthorizer{},
+		WithPolicies(perRequest),
+		WithPerRetryPolicies(chaos),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}),
+	)
+	got, err := c.GetKeyValue(fakeKey, fakeLabel)
+
+	if diff := cmp.Diff(KeyValue{Key: &fakeKey}, got); diff != "" {
+		t.Errorf("GetKeyValue(...): -want, +got:\n%s", diff)
+	}
+	if err != nil {
+		t.Errorf("GetKeyValue(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"2"}, attempts); diff != "" {
+		t.Errorf("WithPerRetryPolicies(...): should retry the injected fault: -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(1, calls); diff != "" {
+		t.Errorf("WithPolicies(...): should run once per request: -want, +got:\n%s", diff)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(KeyValue{Key: &fakeKey})
+	}))
+	defer server.Close()
+
+	var calls []string
+	policy := func(name string) Policy {
+		return func(req *http.Request, next Next) (*http.Response, error) {
+			calls = append(calls, name)
+			return next(req)
+		}
+	}
+	attempt := 0
+	middleware := func(s autorest.Sender) autorest.Sender {
+		return autorest.SenderFunc(func(req *http.Request) (*http.Response, error) {
+			attempt++
+			calls = append(calls, "middleware")
+			if attempt == 1 {
+				return &http.Response{
+					StatusCode: http.StatusServiceUnavailable,
+					Status:     "503 Service Unavailable",
+					Header:     http.Header{},
+					Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+				}, nil
+			}
+			return s.Do(req)
+		})
+	}
+
+	c := NewClient(server.URL, autorest.NullAuthorizer{},
+		WithPerRetryPolicies(policy("first")),
+		WithMiddleware(middleware),
+		WithPerRetryPolicies(policy("last")),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}),
+	)
+	_, err := c.GetKeyValue(fakeKey, fakeLabel)
+
+	if err != nil {
+		t.Errorf("GetKeyValue(...): unexpected error: %v", err)
+	}
+	want := []string{"first", "middleware", "first", "middleware", "last"}
+	if diff := cmp.Diff(want, calls); diff != "" {
+		t.Errorf("WithMiddleware(...): should run as a per retry Policy: -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,181 @@
+package keyvalues
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures how the Client retries idempotent requests
+// (GET, HEAD, PUT and DELETE) that are throttled (429), fail on the server
+// side (5xx) or fail with a transient network error: a timeout, a refused
+// or reset connection, or a truncated response.
+//
+// The delay before each retry doubles from BaseDelay up to MaxDelay, and is
+// randomly reduced by up to the Jitter fraction (between 0 and 1). A delay
+// requested by the service through the retry-after-ms, x-ms-retry-after-ms
+// or Retry-After response headers takes precedence, still capped to
+// MaxDelay.
+//
+// MaxAttempts includes the first attempt, so values lower than 2 disable
+// the retries, which is the zero value behavior.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Jitter      float64
+}
+
+// DefaultRetryPolicy is a RetryPolicy suited to most workloads.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   800 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+	Jitter:      0.2,
+}
+
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// shouldRetry reports whether the outcome of an attempt can be retried.
+func (p RetryPolicy) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if !idempotentMethods[req.Method] || req.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		return isTransient(err)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// isTransient reports whether a request error is a transient network
+// error, as opposed to e.g. an authorization, TLS or URL error.
+func isTransient(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// delay returns the wait before the retry following the given attempt.
+func (p RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if d, ok := retryAfter(resp); ok {
+		return p.capDelay(d)
+	}
+
+	d := p.capDelay(time.Duration(float64(p.BaseDelay) * math.Pow(2, float64(attempt-1))))
+	if p.Jitter > 0 {
+		d -= time.Duration(rand.Float64() * p.Jitter * float64(d))
+	}
+	return d
+}
+
+func (p RetryPolicy) capDelay(d time.Duration) time.Duration {
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return d
+}
+
+// retryAfter reads the delay requested by the service, if any.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	for _, header := range []string{"retry-after-ms", "x-ms-retry-after-ms"} {
+		if ms, err := strconv.ParseInt(resp.Header.Get(header), 10, 64); err == nil && ms >= 0 {
+			return time.Duration(ms) * time.Millisecond, true
+		}
+	}
+
+	value := resp.Header.Get("Retry-After")
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// policy is the pipeline Policy sending the request, retrying it according
+// to the RetryPolicy.
+func (p RetryPolicy) policy(req *http.Request, next Next) (*http.Response, error) {
+	if p.MaxAttempts < 2 {
+		return next(req)
+	}
+	if err := rewindable(req); err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := next(req)
+		if attempt >= p.MaxAttempts || !p.shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		delay := p.delay(attempt, resp)
+		if resp != nil {
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// rewindable buffers the request body, if any, so it can be sent again.
+func rewindable(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	req.Body.Close()
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
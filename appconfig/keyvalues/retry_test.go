@@ -0,0 +1,244 @@
+package keyvalues
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/stone-payments/appconfig-go-sdk/appconfig/test"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	type args struct {
+		policy RetryPolicy
+		call   func(ctx context.Context, c Client) error
+	}
+	type want struct {
+		attempts int
+		err      error
+	}
+
+	// failing answers the first requests with the given status, then
+	// succeeds if the request body, if any, was sent again.
+	failing := func(status, failures int) (http.Handler, *int) {
+		attempts := 0
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			body, _ := ioutil.ReadAll(r.Body)
+			if attempts <= failures {
+				w.Header().Set("retry-after-ms", "1")
+				w.WriteHeader(status)
+				return
+			}
+			if r.Method == http.MethodPut && len(body) == 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(KeyValue{Key: &fakeKey})
+		}), &attempts
+	}
+	get := func(ctx context.Context, c Client) error {
+		_, err := c.GetKeyValueWithContext(ctx, fakeKey, fakeLabel)
+		return err
+	}
+	put := func(ctx context.Context, c Client) error {
+		_, err := c.CreateOrUpdateKeyValueWithContext(ctx, CreateOrUpdateKeyValueArgs{Key: fakeKey, Value: fakeValue})
+		return err
+	}
+	patch := func(ctx context.Context, c Client) error {
+		_, err := c.ArchiveSnapshot(ctx, fakeSnapshot)
+		return err
+	}
+	fastPolicy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	cases := map[string]struct {
+		reason   string
+		status   int
+		failures int
+		args     args
+		want     want
+	}{
+		"RetryThrottled": {
+			reason:   "Should retry a throttled GET request",
+			status:   http.StatusTooManyRequests,
+			failures: 2,
+			args:     args{policy: fastPolicy, call: get},
+			want:     want{attempts: 3, err: nil},
+		},
+		"RetryWithBody": {
+			reason:   "Should send the request body again on retries",
+			status:   http.StatusServiceUnavailable,
+			failures: 1,
+			args:     args{policy: fastPolicy, call: put},
+			want:     want{attempts: 2, err: nil},
+		},
+		"AttemptsExhausted": {
+			reason:   "Should return the last error once the attempts are exhausted",
+			status:   http.StatusInternalServerError,
+			failures: 3,
+			args:     args{policy: fastPolicy, call: get},
+			want:     want{attempts: 3, err: errInternal},
+		},
+		"NonIdempotent": {
+			reason:   "Should not retry non idempotent requests",
+			status:   http.StatusInternalServerError,
+			failures: 1,
+			args:     args{policy: fastPolicy, call: patch},
+			want:     want{attempts: 1, err: errInternal},
+		},
+		"ClientError": {
+			reason:   "Should not retry client errors",
+			status:   http.StatusNotFound,
+			failures: 1,
+			args:     args{policy: fastPolicy, call: get},
+			want:     want{attempts: 1, err: &ResponseError{StatusCode: 404, Status: "404 Not Found"}},
+		},
+		"Disabled": {
+			reason:   "Should not retry with the zero RetryPolicy",
+			status:   http.StatusTooManyRequests,
+			failures: 1,
+			args:     args{policy: RetryPolicy{}, call: get},
+			want:     want{attempts: 1, err: &ResponseError{StatusCode: 429, Status: "429 Too Many Requests"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			handler, attempts := failing(tc.status, tc.failures)
+			server := httptest.NewServer(handler)
+			defer server.Close()
+			c := NewClient(server.URL, autorest.NullAuthorizer{})
+			c.(*ClientImpl).RetryPolicy = tc.args.policy
+
+			err := tc.args.call(context.Background(), c)
+
+			if diff := cmp.Diff(tc.want.attempts, *attempts); diff != "" {
+				t.Errorf("%s: -want attempts, +got attempts:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("%s: -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	type args struct {
+		policy  RetryPolicy
+		attempt int
+		header  http.Header
+	}
+	type want struct {
+		delay time.Duration
+	}
+
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 3 * time.Second}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Exponential": {
+			reason: "Should double the delay on each attempt",
+			args:   args{policy: policy, attempt: 2, header: http.Header{}},
+			want:   want{delay: 2 * time.Second},
+		},
+		"MaxDelay": {
+			reason: "Should cap the delay to MaxDelay",
+			args:   args{policy: policy, attempt: 4, header: http.Header{}},
+			want:   want{delay: 3 * time.Second},
+		},
+		"RetryAfterMs": {
+			reason: "Should honor the retry-after-ms header",
+			args:   args{policy: policy, attempt: 1, header: http.Header{"Retry-After-Ms": []string{"250"}}},
+			want:   want{delay: 250 * time.Millisecond},
+		},
+		"RetryAfterSeconds": {
+			reason: "Should honor the Retry-After header",
+			args:   args{policy: policy, attempt: 1, header: http.Header{"Retry-After": []string{"2"}}},
+			want:   want{delay: 2 * time.Second},
+		},
+		"RetryAfterMaxDelay": {
+			reason: "Should cap the Retry-After delay to MaxDelay",
+			args:   args{policy: policy, attempt: 1, header: http.Header{"Retry-After": []string{"3600"}}},
+			want:   want{delay: 3 * time.Second},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.args.policy.delay(tc.args.attempt, &http.Response{Header: tc.args.header})
+			if diff := cmp.Diff(tc.want.delay, got); diff != "" {
+				t.Errorf("%s: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsTransient(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   bool
+	}{
+		"Timeout": {
+			reason: "Should retry the timeouts",
+			err:    &url.Error{Op: "Get", URL: fakeIDs, Err: timeoutError{}},
+			want:   true,
+		},
+		"ConnectionReset": {
+			reason: "Should retry the reset connections",
+			err:    &url.Error{Op: "Get", URL: fakeIDs, Err: &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}},
+			want:   true,
+		},
+		"ConnectionRefused": {
+			reason: "Should retry the refused connections",
+			err:    &url.Error{Op: "Get", URL: fakeIDs, Err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}},
+			want:   true,
+		},
+		"UnexpectedEOF": {
+			reason: "Should retry the truncated responses",
+			err:    &url.Error{Op: "Get", URL: fakeIDs, Err: io.ErrUnexpectedEOF},
+			want:   true,
+		},
+		"Authorization": {
+			reason: "Should not retry the token acquisition failures",
+			err:    autorest.NewError("azure.BearerAuthorizer", "WithAuthorization", "Failed to refresh the Token"),
+			want:   false,
+		},
+		"InvalidURL": {
+			reason: "Should not retry the malformed URLs",
+			err:    &url.Error{Op: "parse", URL: ":", Err: errors.New("missing protocol scheme")},
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := isTransient(tc.err); got != tc.want {
+				t.Errorf("isTransient(...): %s: want %t, got %t", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
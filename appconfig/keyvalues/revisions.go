@@ -0,0 +1,44 @@
+package keyvalues
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// ListRevisions returns the revision history of App Configuration
+// KeyValues, filtered by the provided Key and/or Label. All result pages
+// are fetched by following the continuation links.
+//
+// Optional: Key; Label (if not specified, it implies any Key/Label);
+// Select; AsOf
+func (client *ClientImpl) ListRevisions(ctx context.Context, args ListRevisionsArgs) (result KeyValues, err error) {
+	ctx, op := client.startOperation(ctx, "appconfig.ListRevisions", AttributeKey, args.Key, AttributeLabel, args.Label)
+	defer func() {
+		op.set(AttributeItemCount, len(result.Items))
+		op.end(err)
+	}()
+
+	return collectKeyValues(ctx, client.NewListRevisionsPager(args))
+}
+
+// NewListRevisionsPager returns a KeyValuesPager that lazily fetches the
+// pages of the revision history of App Configuration KeyValues.
+//
+// Optional: Key; Label (if not specified, it implies any Key/Label);
+// Select; AsOf
+func (client *ClientImpl) NewListRevisionsPager(args ListRevisionsArgs) KeyValuesPager {
+	attributes := []string{AttributeKey, args.Key, AttributeLabel, args.Label}
+	if args.Key == "" {
+		args.Key = "*"
+	}
+	if args.Label == "" {
+		args.Label = "*"
+	}
+
+	decorators := append(withSelect(args.Select), withAcceptDatetime(args.AsOf)...)
+	return newKeyValuesPager(client, "appconfig.ListRevisions", attributes, func(ctx context.Context) (*http.Request, error) {
+		return client.createListRequest(ctx, "/revisions", args.Label, args.Key, append(decorators, autorest.AsGet())...)
+	}, decorators...)
+}
@@ -0,0 +1,109 @@
+package keyvalues
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/stone-payments/appconfig-go-sdk/appconfig/test"
+)
+
+func TestListRevisions(t *testing.T) {
+	type args struct {
+		ListRevisionsArgs
+	}
+	type want struct {
+		kvs KeyValues
+		err error
+	}
+
+	asOf := time.Date(2021, time.August, 2, 15, 4, 5, 0, time.UTC)
+
+	cases := map[string]struct {
+		reason  string
+		handler http.Handler
+		args    args
+		want    want
+	}{
+		"ListRevisionsSucessfully": {
+			reason: "Should return every revision page with the selected fields",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.HasPrefix(r.URL.Path, "/revisions") {
+					if r.URL.Query().Get("key") != fakeKey ||
+						r.URL.Query().Get("$select") != "key,etag" ||
+						r.Header.Get("Accept-Datetime") != "Mon, 02 Aug 2021 15:04:05 GMT" {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					response := KeyValues{Items: []KeyValue{{Key: &fakeKey}}}
+					if r.URL.Query().Get("after") == "" {
+						w.Header().Set("Link", `</revisions?after=fake&key=fakeKey&$select=key,etag&api-version=1.0>; rel="next"`)
+						response = KeyValues{Items: []KeyValue{{Key: &fakeKey, Etag: &fakeEtag}}}
+					}
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&response)
+				}
+			}),
+			args: args{
+				ListRevisionsArgs: ListRevisionsArgs{
+					Key:    fakeKey,
+					Select: []Field{FieldKey, FieldEtag},
+					AsOf:   asOf,
+				},
+			},
+			want: want{
+				kvs: KeyValues{Items: []KeyValue{{Key: &fakeKey, Etag: &fakeEtag}, {Key: &fakeKey}}},
+				err: nil,
+			},
+		},
+		"ListRevisionsInternalError": {
+			reason: "Should return an error if the list returns Status Code greater than 399",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.Contains(r.URL.String(), "revisions") {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}),
+			args: args{
+				ListRevisionsArgs: ListRevisionsArgs{},
+			},
+			want: want{
+				kvs: KeyValues{},
+				err: errInternal,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+			args := NewClientAzureADArgs{ClientID: fakeIDs, TenantID: fakeIDs, ClientSecret: fakeIDs, ResourceEndpoint: server.URL, AADEndpoint: server.URL}
+			c, _ := NewClientAzureAD(args)
+
+			got, err := c.ListRevisions(context.Background(), tc.args.ListRevisionsArgs)
+
+			if diff := cmp.Diff(tc.want.kvs, got); diff != "" {
+				t.Errorf("ListRevisions(...): -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("ListRevisions(...): -want error, +got error:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package keyvalues
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+const (
+	snapshotContentType   = "application/vnd.microsoft.appconfig.snapshot+json"
+	mergePatchContentType = "application/merge-patch+json"
+	defaultPollInterval   = time.Second
+)
+
+// snapshotOperation represents the status of the Snapshot
+// creation long-running operation.
+type snapshotOperation struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// CreateSnapshot creates an App Configuration Snapshot and waits for its
+// creation operation to complete, returning the ready Snapshot.
+//
+// Required parameters: Name; Filters
+//
+// Optional parameters: CompositionType; RetentionPeriod; Tags; PollInterval
+func (client *ClientImpl) CreateSnapshot(ctx context.Context, args CreateSnapshotArgs) (result Snapshot, err error) {
+	ctx, op := client.startOperation(ctx, "appconfig.CreateSnapshot", AttributeSnapshot, args.Name)
+	defer func() { op.end(err) }()
+
+	req, err := client.createSnapshotRequest(
+		ctx,
+		args.Name,
+		autorest.AsContentType(snapshotContentType),
+		autorest.AsPut(),
+		autorest.WithJSON(args),
+	)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	response, err := client.sendRequest(req)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	response.Body.Close()
+
+	operation := response.Header.Get("Operation-Location")
+	if operation == "" {
+		operation = fmt.Sprintf("/operations?snapshot=%s&api-version=%s", url.QueryEscape(args.Name), latestAPIVersion)
+	}
+
+	interval := args.PollInterval
+	if interval == 0 {
+		interval = defaultPollInterval
+	}
+	if err = client.pollSnapshotOperation(ctx, operation, interval); err != nil {
+		return Snapshot{}, err
+	}
+
+	return client.GetSnapshot(ctx, args.Name)
+}
+
+// pollSnapshotOperation checks the operation status until it completes.
+func (client *ClientImpl) pollSnapshotOperation(ctx context.Context, operation string, interval time.Duration) error {
+	for {
+		req, err := client.createNextRequest(ctx, operation)
+		if err != nil {
+			return err
+		}
+
+		response, err := client.sendRequest(req)
+		if err != nil {
+			return err
+		}
+
+		var result snapshotOperation
+		if err = getJSON(response, &result); err != nil {
+			return err
+		}
+
+		switch strings.ToLower(result.Status) {
+		case "succeeded":
+			return nil
+		case "failed", "canceled":
+			if result.Error != nil {
+				return fmt.Errorf("snapshot operation %s %s: %s - %s", result.ID, result.Status, result.Error.Code, result.Error.Message)
+			}
+			return fmt.Errorf("snapshot operation %s %s", result.ID, result.Status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// GetSnapshot gets an App Configuration Snapshot.
+func (client *ClientImpl) GetSnapshot(ctx context.Context, name string) (result Snapshot, err error) {
+	ctx, op := client.startOperation(ctx, "appconfig.GetSnapshot", AttributeSnapshot, name)
+	defer func() { op.end(err) }()
+
+	req, err := client.createSnapshotRequest(ctx, name, autorest.AsGet())
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return client.sendSnapshotRequest(req)
+}
+
+// ListSnapshots returns an array of App Configuration Snapshots, filtered
+// by the provided Name and/or Status. All result pages are fetched by
+// following the continuation links.
+//
+// Optional: Name (if not specified, it implies any Snapshot); Status
+func (client *ClientImpl) ListSnapshots(ctx context.Context, args ListSnapshotsArgs) (result Snapshots, err error) {
+	ctx, op := client.startOperation(ctx, "appconfig.ListSnapshots", AttributeSnapshot, args.Name)
+	defer func() {
+		op.set(AttributeItemCount, len(result.Items))
+		op.end(err)
+	}()
+
+	queryParameters := map[string]interface{}{
+		"api-version": latestAPIVersion,
+	}
+	if args.Name != "" {
+		queryParameters["name"] = autorest.Encode("query", args.Name)
+	}
+	if len(args.Status) > 0 {
+		queryParameters["status"] = strings.Join(args.Status, ",")
+	}
+
+	req, err := client.createQueryRequest(ctx, "/snapshots", queryParameters, autorest.AsGet())
+	if err != nil {
+		return Snapshots{}, err
+	}
+
+	err = client.collectPages(ctx, req, func(response *http.Response) error {
+		var page Snapshots
+		if err := getJSON(response, &page); err != nil {
+			return err
+		}
+		result.Items = append(result.Items, page.Items...)
+		return nil
+	})
+	if err != nil {
+		return Snapshots{}, err
+	}
+	return result, nil
+}
+
+// ArchiveSnapshot archives a ready App Configuration Snapshot. An archived
+// Snapshot expires once its retention period is over.
+func (client *ClientImpl) ArchiveSnapshot(ctx context.Context, name string) (result Snapshot, err error) {
+	ctx, op := client.startOperation(ctx, "appconfig.ArchiveSnapshot", AttributeSnapshot, name)
+	defer func() { op.end(err) }()
+
+	return client.updateSnapshotStatus(ctx, name, SnapshotStatusArchived)
+}
+
+// RecoverSnapshot recovers an archived App Configuration Snapshot,
+// making it ready again.
+func (client *ClientImpl) RecoverSnapshot(ctx context.Context, name string) (result Snapshot, err error) {
+	ctx, op := client.startOperation(ctx, "appconfig.RecoverSnapshot", AttributeSnapshot, name)
+	defer func() { op.end(err) }()
+
+	return client.updateSnapshotStatus(ctx, name, SnapshotStatusReady)
+}
+
+func (client *ClientImpl) updateSnapshotStatus(ctx context.Context, name, status string) (Snapshot, error) {
+	req, err := client.createSnapshotRequest(
+		ctx,
+		name,
+		autorest.AsContentType(mergePatchContentType),
+		autorest.AsPatch(),
+		autorest.WithJSON(map[string]string{"status": status}),
+	)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return client.sendSnapshotRequest(req)
+}
+
+func (client *ClientImpl) sendSnapshotRequest(req *http.Request) (Snapshot, error) {
+	response, err := client.sendRequest(req)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	var result Snapshot
+	if err = getJSON(response, &result); err != nil {
+		return Snapshot{}, err
+	}
+	return result, nil
+}
+
+func (client *ClientImpl) createSnapshotRequest(ctx context.Context, name string, additionalDecorator ...autorest.PrepareDecorator) (*http.Request, error) {
+	queryParameters := map[string]interface{}{
+		"api-version": latestAPIVersion,
+	}
+	return client.createQueryRequest(ctx, "/snapshots/"+url.PathEscape(name), queryParameters, additionalDecorator...)
+}
@@ -0,0 +1,345 @@
+package keyvalues
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/stone-payments/appconfig-go-sdk/appconfig/test"
+)
+
+var (
+	fakeSnapshot = "fakeSnapshot"
+	readyStatus  = SnapshotStatusReady
+)
+
+func TestCreateSnapshot(t *testing.T) {
+	type args struct {
+		CreateSnapshotArgs
+	}
+	type want struct {
+		snapshot Snapshot
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler http.Handler
+		args    args
+		want    want
+	}{
+		"CreateSnapshotSucessfully": {
+			reason: "Should poll the operation until it succeeds and return the ready Snapshot",
+			handler: func() http.Handler {
+				polls := 0
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					defer r.Body.Close()
+					switch {
+					case strings.Contains(r.URL.String(), "oauth"):
+						w.WriteHeader(http.StatusOK)
+						_ = json.NewEncoder(w).Encode(&token{})
+					case r.Method == http.MethodPut && r.URL.Path == "/snapshots/fakeSnapshot":
+						var body CreateSnapshotArgs
+						_ = json.NewDecoder(r.Body).Decode(&body)
+						if len(body.Filters) != 1 || body.CompositionType != CompositionTypeKeyLabel {
+							w.WriteHeader(http.StatusBadRequest)
+							return
+						}
+						w.Header().Set("Operation-Location", "/operations?snapshot=fakeSnapshot&api-version=2023-11-01")
+						w.WriteHeader(http.StatusCreated)
+						_ = json.NewEncoder(w).Encode(Snapshot{Name: &fakeSnapshot})
+					case r.URL.Path == "/operations":
+						polls++
+						status := "Running"
+						if polls > 1 {
+							status = "Succeeded"
+						}
+						w.WriteHeader(http.StatusOK)
+						_ = json.NewEncoder(w).Encode(snapshotOperation{ID: fakeIDs, Status: status})
+					case r.Method == http.MethodGet && r.URL.Path == "/snapshots/fakeSnapshot":
+						w.WriteHeader(http.StatusOK)
+						_ = json.NewEncoder(w).Encode(Snapshot{Name: &fakeSnapshot, Status: &readyStatus})
+					}
+				})
+			}(),
+			args: args{
+				CreateSnapshotArgs: CreateSnapshotArgs{
+					Name:            fakeSnapshot,
+					Filters:         []SnapshotFilter{{Key: "app:*", Label: fakeLabel}},
+					CompositionType: CompositionTypeKeyLabel,
+					PollInterval:    time.Millisecond,
+				},
+			},
+			want: want{
+				snapshot: Snapshot{Name: &fakeSnapshot, Status: &readyStatus},
+				err:      nil,
+			},
+		},
+		"CreateSnapshotOperationFailed": {
+			reason: "Should return an error if the creation operation fails",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				switch {
+				case strings.Contains(r.URL.String(), "oauth"):
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				case r.Method == http.MethodPut:
+					w.WriteHeader(http.StatusCreated)
+				case r.URL.Path == "/operations":
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte(`{"id":"fake","status":"Failed","error":{"code":"Conflict","message":"boom"}}`))
+				}
+			}),
+			args: args{
+				CreateSnapshotArgs: CreateSnapshotArgs{
+					Name:    fakeSnapshot,
+					Filters: []SnapshotFilter{{Key: "app:*"}},
+				},
+			},
+			want: want{
+				snapshot: Snapshot{},
+				err:      errors.New("snapshot operation fake Failed: Conflict - boom"),
+			},
+		},
+		"CreateSnapshotInternalError": {
+			reason: "Should return an error if the request returns Status Code greater than 399",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.Contains(r.URL.String(), "snapshots") {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}),
+			args: args{
+				CreateSnapshotArgs: CreateSnapshotArgs{Name: fakeSnapshot},
+			},
+			want: want{
+				snapshot: Snapshot{},
+				err:      errInternal,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+			args := NewClientAzureADArgs{ClientID: fakeIDs, TenantID: fakeIDs, ClientSecret: fakeIDs, ResourceEndpoint: server.URL, AADEndpoint: server.URL}
+			c, _ := NewClientAzureAD(args)
+
+			got, err := c.CreateSnapshot(context.Background(), tc.args.CreateSnapshotArgs)
+
+			if diff := cmp.Diff(tc.want.snapshot, got); diff != "" {
+				t.Errorf("CreateSnapshot(...): -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("CreateSnapshot(...): -want error, +got error:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestListSnapshots(t *testing.T) {
+	type args struct {
+		ListSnapshotsArgs
+	}
+	type want struct {
+		snapshots Snapshots
+		err       error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler http.Handler
+		args    args
+		want    want
+	}{
+		"ListSnapshotsSucessfully": {
+			reason: "Should return the Snapshots of every page matching the filters",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if r.URL.Path == "/snapshots" {
+					if r.URL.Query().Get("name") != "fake*" || r.URL.Query().Get("status") != "ready,archived" {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					response := Snapshots{Items: []Snapshot{{Status: &readyStatus}}}
+					if r.URL.Query().Get("after") == "" {
+						w.Header().Set("Link", `</snapshots?after=fake&name=fake*&status=ready,archived&api-version=2023-11-01>; rel="next"`)
+						response = Snapshots{Items: []Snapshot{{Name: &fakeSnapshot}}}
+					}
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&response)
+				}
+			}),
+			args: args{
+				ListSnapshotsArgs: ListSnapshotsArgs{
+					Name:   "fake*",
+					Status: []string{SnapshotStatusReady, SnapshotStatusArchived},
+				},
+			},
+			want: want{
+				snapshots: Snapshots{Items: []Snapshot{{Name: &fakeSnapshot}, {Status: &readyStatus}}},
+				err:       nil,
+			},
+		},
+		"ListSnapshotsEncodedFilter": {
+			reason: "Should send the Name filter with its plus and percent signs",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if r.URL.Path == "/snapshots" {
+					if r.URL.Query().Get("name") != "a+b 100%" {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(Snapshots{Items: []Snapshot{{Name: &fakeSnapshot}}})
+				}
+			}),
+			args: args{
+				ListSnapshotsArgs: ListSnapshotsArgs{Name: "a+b 100%"},
+			},
+			want: want{
+				snapshots: Snapshots{Items: []Snapshot{{Name: &fakeSnapshot}}},
+				err:       nil,
+			},
+		},
+		"ListSnapshotsInternalError": {
+			reason: "Should return an error if the list returns Status Code greater than 399",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Body.Close()
+				if strings.Contains(r.URL.String(), "oauth") {
+					w.WriteHeader(http.StatusOK)
+					_ = json.NewEncoder(w).Encode(&token{})
+				}
+				if strings.Contains(r.URL.String(), "snapshots") {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}),
+			want: want{
+				snapshots: Snapshots{},
+				err:       errInternal,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+			args := NewClientAzureADArgs{ClientID: fakeIDs, TenantID: fakeIDs, ClientSecret: fakeIDs, ResourceEndpoint: server.URL, AADEndpoint: server.URL}
+			c, _ := NewClientAzureAD(args)
+
+			got, err := c.ListSnapshots(context.Background(), tc.args.ListSnapshotsArgs)
+
+			if diff := cmp.Diff(tc.want.snapshots, got); diff != "" {
+				t.Errorf("ListSnapshots(...): -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("ListSnapshots(...): -want error, +got error:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUpdateSnapshotStatus(t *testing.T) {
+	type args struct {
+		name    string
+		recover bool
+	}
+	type want struct {
+		snapshot Snapshot
+		err      error
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		if strings.Contains(r.URL.String(), "oauth") {
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(&token{})
+		}
+		if r.URL.Path == "/snapshots/fakeSnapshot" {
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if r.Method != http.MethodPatch || r.Header.Get("Content-Type") != mergePatchContentType {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			status := body["status"]
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(Snapshot{Name: &fakeSnapshot, Status: &status})
+		}
+	})
+	archivedStatus := SnapshotStatusArchived
+
+	cases := map[string]struct {
+		reason  string
+		handler http.Handler
+		args    args
+		want    want
+	}{
+		"ArchiveSnapshotSucessfully": {
+			reason:  "Should return the archived Snapshot",
+			handler: handler,
+			args: args{
+				name: fakeSnapshot,
+			},
+			want: want{
+				snapshot: Snapshot{Name: &fakeSnapshot, Status: &archivedStatus},
+				err:      nil,
+			},
+		},
+		"RecoverSnapshotSucessfully": {
+			reason:  "Should return the recovered Snapshot",
+			handler: handler,
+			args: args{
+				name:    fakeSnapshot,
+				recover: true,
+			},
+			want: want{
+				snapshot: Snapshot{Name: &fakeSnapshot, Status: &readyStatus},
+				err:      nil,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+			args := NewClientAzureADArgs{ClientID: fakeIDs, TenantID: fakeIDs, ClientSecret: fakeIDs, ResourceEndpoint: server.URL, AADEndpoint: server.URL}
+			c, _ := NewClientAzureAD(args)
+
+			update := c.ArchiveSnapshot
+			if tc.args.recover {
+				update = c.RecoverSnapshot
+			}
+			got, err := update(context.Background(), tc.args.name)
+
+			if diff := cmp.Diff(tc.want.snapshot, got); diff != "" {
+				t.Errorf("ArchiveSnapshot(...): -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("ArchiveSnapshot(...): -want error, +got error:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package keyvalues
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const syncTokenHeader = "Sync-Token"
+
+// syncToken is a consistency token of an App Configuration replica, e.g.
+// `jtqGc1I4=MDoyOA==;sn=28`. Tokens with the same ID are ordered by their
+// sequence number.
+type syncToken struct {
+	id    string
+	value string
+	sn    int64
+}
+
+// syncTokens holds the latest sync token of each ID. It is safe for
+// concurrent use.
+type syncTokens struct {
+	mu     sync.Mutex
+	tokens map[string]syncToken
+}
+
+// parseSyncTokens parses a comma separated list of sync tokens.
+func parseSyncTokens(header string) ([]syncToken, error) {
+	var tokens []syncToken
+	for _, raw := range strings.Split(header, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		parts := strings.Split(raw, ";")
+		id, value, ok := cutSyncToken(parts[0], "=")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid sync token %q: expected id=value;sn=number", raw)
+		}
+		token := syncToken{id: id, value: value, sn: -1}
+		for _, param := range parts[1:] {
+			name, sn, ok := cutSyncToken(param, "=")
+			if !ok || name != "sn" {
+				continue
+			}
+			n, err := strconv.ParseInt(sn, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid sync token %q: %w", raw, err)
+			}
+			token.sn = n
+		}
+		if token.sn < 0 {
+			return nil, fmt.Errorf("invalid sync token %q: missing sn", raw)
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// cutSyncToken slices s around the first sep, trimming the spaces.
+func cutSyncToken(s, sep string) (string, string, bool) {
+	i := strings.Index(s, sep)
+	if i < 0 {
+		return "", "", false
+	}
+	return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+len(sep):]), true
+}
+
+// update merges the tokens of a Sync-Token header, keeping the one with
+// the highest sequence number of each ID.
+func (s *syncTokens) update(header string) error {
+	tokens, err := parseSyncTokens(header)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tokens == nil {
+		s.tokens = map[string]syncToken{}
+	}
+	for _, token := range tokens {
+		if current, ok := s.tokens[token.id]; !ok || token.sn > current.sn {
+			s.tokens[token.id] = token
+		}
+	}
+	return nil
+}
+
+// header returns the Sync-Token header value echoing the tokens, or an
+// empty string if there are none.
+func (s *syncTokens) header() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values := make([]string, 0, len(s.tokens))
+	for _, token := range s.tokens {
+		values = append(values, token.id+"="+token.value)
+	}
+	sort.Strings(values)
+	return strings.Join(values, ",")
+}
+
+// UpdateSyncToken merges a sync token, e.g. received from an Event Grid
+// notification, so the following requests observe at least its changes.
+func (client *ClientImpl) UpdateSyncToken(token string) error {
+	return client.syncTokens.update(token)
+}
+
+// syncTokenPolicy is the pipeline Policy sending the sync tokens with each
+// request attempt and merging the ones of the response.
+func (client *ClientImpl) syncTokenPolicy(req *http.Request, next Next) (*http.Response, error) {
+	if header := client.syncTokens.header(); header != "" {
+		req.Header.Set(syncTokenHeader, header)
+	}
+
+	resp, err := next(req)
+	if resp != nil {
+		for _, header := range resp.Header.Values(syncTokenHeader) {
+			// Malformed tokens are ignored, they only weaken consistency.
+			_ = client.syncTokens.update(header)
+		}
+	}
+	return resp, err
+}
@@ -0,0 +1,105 @@
+package keyvalues
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSyncTokens(t *testing.T) {
+	type args struct {
+		responseTokens []string
+		updates        []string
+	}
+	type want struct {
+		sent      []string
+		updateErr bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"EchoResponseTokens": {
+			reason: "Should send the sync tokens received in previous responses",
+			args: args{
+				responseTokens: []string{"jtqGc1I4=MDoyOA==;sn=28", "zAJw6V16=Njo1OA==;sn=58"},
+			},
+			want: want{
+				sent: []string{"", "jtqGc1I4=MDoyOA==", "jtqGc1I4=MDoyOA==,zAJw6V16=Njo1OA=="},
+			},
+		},
+		"HighestSequenceNumber": {
+			reason: "Should keep the sync token with the highest sequence number of each ID",
+			args: args{
+				responseTokens: []string{"jtqGc1I4=MDoyOA==;sn=28", "jtqGc1I4=MDoyNw==;sn=27"},
+			},
+			want: want{
+				sent: []string{"", "jtqGc1I4=MDoyOA==", "jtqGc1I4=MDoyOA=="},
+			},
+		},
+		"UpdateSyncToken": {
+			reason: "Should send the sync tokens of UpdateSyncToken",
+			args: args{
+				updates:        []string{"jtqGc1I4=MDoyOQ==;sn=29, zAJw6V16=Njo1OA==;sn=58"},
+				responseTokens: []string{"jtqGc1I4=MDoyOA==;sn=28"},
+			},
+			want: want{
+				sent: []string{
+					"jtqGc1I4=MDoyOQ==,zAJw6V16=Njo1OA==",
+					"jtqGc1I4=MDoyOQ==,zAJw6V16=Njo1OA==",
+					"jtqGc1I4=MDoyOQ==,zAJw6V16=Njo1OA==",
+				},
+			},
+		},
+		"InvalidSyncToken": {
+			reason: "Should reject a sync token without sequence number",
+			args: args{
+				updates: []string{"jtqGc1I4=MDoyOA=="},
+			},
+			want: want{
+				sent:      []string{"", "", ""},
+				updateErr: true,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var sent []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				sent = append(sent, r.Header.Get(syncTokenHeader))
+				if i := len(sent) - 1; i < len(tc.args.responseTokens) {
+					w.Header().Set(syncTokenHeader, tc.args.responseTokens[i])
+				}
+				_ = json.NewEncoder(w).Encode(KeyValue{Key: &fakeKey})
+			}))
+			defer server.Close()
+
+			c := NewClient(server.URL, autorest.NullAuthorizer{})
+			var updateErr error
+			for _, token := range tc.args.updates {
+				if err := c.UpdateSyncToken(token); err != nil {
+					updateErr = err
+				}
+			}
+			for i := 0; i < 3; i++ {
+				if _, err := c.GetKeyValue(fakeKey, fakeLabel); err != nil {
+					t.Fatalf("%s: unexpected error: %v", tc.reason, err)
+				}
+			}
+
+			if diff := cmp.Diff(tc.want.updateErr, updateErr != nil); diff != "" {
+				t.Errorf("%s: -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.sent, sent); diff != "" {
+				t.Errorf("%s: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package keyvalues
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Span attributes set by the Client.
+const (
+	AttributeEndpoint   = "appconfig.endpoint"
+	AttributeKey        = "appconfig.key"
+	AttributeLabel      = "appconfig.label"
+	AttributeSnapshot   = "appconfig.snapshot"
+	AttributeItemCount  = "appconfig.item_count"
+	AttributeAttempts   = "appconfig.attempts"
+	AttributeStatusCode = "http.status_code"
+)
+
+// Tracer creates a Span for each Client operation, named after it, e.g.
+// appconfig.ListKeyValues, and propagates the trace context of the
+// requests. See the README for an OpenTelemetry adapter.
+type Tracer interface {
+	// Start starts a Span, returning a context carrying it.
+	Start(ctx context.Context, name string) (context.Context, Span)
+
+	// Inject sets the trace context headers of a request sent in ctx, e.g.
+	// the W3C traceparent and tracestate headers.
+	Inject(ctx context.Context, header http.Header)
+}
+
+// Span is a Client operation in progress.
+type Span interface {
+	// SetAttribute sets a string or int attribute.
+	SetAttribute(key string, value interface{})
+
+	// RecordError records the error failing the operation.
+	RecordError(err error)
+
+	// End ends the Span.
+	End()
+}
+
+type operationKey struct{}
+
+// operation is a Client operation in progress, carried by the context of
+// its requests. Its span is nil if the Client has no Tracer. The attempts
+// are the ones of its current request, e.g. of a single page or poll.
+type operation struct {
+	name     string
+	span     Span
+	attempts int
+}
+
+// startOperation starts a Client operation and its Span, setting the given
+// alternating attribute names and values. Empty values are skipped.
+func (client *ClientImpl) startOperation(ctx context.Context, name string, attributes ...string) (context.Context, *operation) {
+	op := &operation{name: name}
+	if client.Tracer != nil {
+		ctx, op.span = client.Tracer.Start(ctx, name)
+		op.set(AttributeEndpoint, client.Endpoint)
+		for i := 0; i+1 < len(attributes); i += 2 {
+			if attributes[i+1] != "" {
+				op.set(attributes[i], attributes[i+1])
+			}
+		}
+	}
+	return context.WithValue(ctx, operationKey{}, op), op
+}
+
+// operationFrom returns the operation carried by ctx, if any.
+func operationFrom(ctx context.Context) (*operation, bool) {
+	op, ok := ctx.Value(operationKey{}).(*operation)
+	return op, ok
+}
+
+func (op *operation) set(key string, value interface{}) {
+	if op.span != nil {
+		op.span.SetAttribute(key, value)
+	}
+}
+
+// end ends the operation Span, recording err unless it is ErrNotModified.
+func (op *operation) end(err error) {
+	if op.span == nil {
+		return
+	}
+	if err != nil && !errors.Is(err, ErrNotModified) {
+		op.span.RecordError(err)
+	}
+	op.span.End()
+}
+
+// tracingPolicy is the pipeline Policy propagating the trace context of
+// each request attempt, and recording the attempts of the request on the
+// operation Span.
+func (client *ClientImpl) tracingPolicy(req *http.Request, next Next) (*http.Response, error) {
+	if client.Tracer == nil {
+		return next(req)
+	}
+	client.Tracer.Inject(req.Context(), req.Header)
+
+	resp, err := next(req)
+	if op, ok := operationFrom(req.Context()); ok {
+		op.attempts++
+		op.set(AttributeAttempts, op.attempts)
+		if resp != nil {
+			op.set(AttributeStatusCode, resp.StatusCode)
+		}
+	}
+	return resp, err
+}
@@ -0,0 +1,221 @@
+package keyvalues
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/google/go-cmp/cmp"
+)
+
+const fakeTraceParent = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+
+type fakeSpan struct {
+	Name       string
+	Attributes map[string]interface{}
+	Errors     int
+	Ended      bool
+}
+
+func (s *fakeSpan) SetAttribute(key string, value interface{}) { s.Attributes[key] = value }
+func (s *fakeSpan) RecordError(err error)                      { s.Errors++ }
+func (s *fakeSpan) End()                                       { s.Ended = true }
+
+type fakeTracer struct {
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &fakeSpan{Name: name, Attributes: map[string]interface{}{}}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func (t *fakeTracer) Inject(ctx context.Context, header http.Header) {
+	header.Set("traceparent", fakeTraceParent)
+}
+
+func TestTracer(t *testing.T) {
+	type args struct {
+		call func(ctx context.Context, c Client) error
+	}
+	type want struct {
+		spans []*fakeSpan
+	}
+
+	// handler fails the first request with the given status, then answers
+	// traced requests.
+	handler := func(status int) http.HandlerFunc {
+		requests := 0
+		return func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests == 1 && status != http.StatusOK {
+				w.WriteHeader(status)
+				return
+			}
+			if r.Header.Get("traceparent") != fakeTraceParent {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if r.URL.Path == "/kv" {
+				_ = json.NewEncoder(w).Encode(KeyValues{Items: []KeyValue{{Key: &fakeKey}, {Key: &fakeKey}}})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(KeyValue{Key: &fakeKey})
+		}
+	}
+
+	// pages answers two pages of KeyValues, failing the first request of
+	// the second page with a 503.
+	pages := func() http.HandlerFunc {
+		failed := false
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("after") == "" {
+				w.Header().Set("Link", `</kv?after=fake>; rel="next"`)
+				_ = json.NewEncoder(w).Encode(KeyValues{Items: []KeyValue{{Key: &fakeKey}, {Key: &fakeKey}}})
+				return
+			}
+			if !failed {
+				failed = true
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(KeyValues{Items: []KeyValue{{Key: &fakeKey}}})
+		}
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		args    args
+		want    want
+	}{
+		"GetKeyValue": {
+			reason:  "Should trace the operation and its attempts",
+			handler: handler(http.StatusServiceUnavailable),
+			args: args{call: func(ctx context.Context, c Client) error {
+				_, err := c.GetKeyValueWithContext(ctx, fakeKey, fakeLabel)
+				return err
+			}},
+			want: want{spans: []*fakeSpan{{
+				Name: "appconfig.GetKeyValue",
+				Attributes: map[string]interface{}{
+					AttributeKey:        fakeKey,
+					AttributeLabel:      fakeLabel,
+					AttributeAttempts:   2,
+					AttributeStatusCode: http.StatusOK,
+				},
+				Ended: true,
+			}}},
+		},
+		"ListKeyValues": {
+			reason:  "Should trace the item count of list operations",
+			handler: handler(http.StatusOK),
+			args: args{call: func(ctx context.Context, c Client) error {
+				_, err := c.ListKeyValuesWithContext(ctx, ListKeyValuesArgs{Key: "app:*"})
+				return err
+			}},
+			want: want{spans: []*fakeSpan{{
+				Name: "appconfig.ListKeyValues",
+				Attributes: map[string]interface{}{
+					AttributeKey:        "app:*",
+					AttributeAttempts:   1,
+					AttributeStatusCode: http.StatusOK,
+					AttributeItemCount:  2,
+				},
+				Ended: true,
+			}}},
+		},
+		"ListKeyValuesPages": {
+			reason:  "Should trace the attempts of the last request rather than of every page",
+			handler: pages(),
+			args: args{call: func(ctx context.Context, c Client) error {
+				_, err := c.ListKeyValuesWithContext(ctx, ListKeyValuesArgs{})
+				return err
+			}},
+			want: want{spans: []*fakeSpan{{
+				Name: "appconfig.ListKeyValues",
+				Attributes: map[string]interface{}{
+					AttributeAttempts:   2,
+					AttributeStatusCode: http.StatusOK,
+					AttributeItemCount:  3,
+				},
+				Ended: true,
+			}}},
+		},
+		"Pager": {
+			reason:  "Should trace each page of a pager used on its own",
+			handler: pages(),
+			args: args{call: func(ctx context.Context, c Client) error {
+				pager := c.NewListKeyValuesPager(ListKeyValuesArgs{Key: "app:*"})
+				for pager.Next(ctx) {
+				}
+				return pager.Err()
+			}},
+			want: want{spans: []*fakeSpan{
+				{
+					Name: "appconfig.ListKeyValues",
+					Attributes: map[string]interface{}{
+						AttributeKey:        "app:*",
+						AttributeAttempts:   1,
+						AttributeStatusCode: http.StatusOK,
+						AttributeItemCount:  2,
+					},
+					Ended: true,
+				},
+				{
+					Name: "appconfig.ListKeyValues",
+					Attributes: map[string]interface{}{
+						AttributeKey:        "app:*",
+						AttributeAttempts:   2,
+						AttributeStatusCode: http.StatusOK,
+						AttributeItemCount:  1,
+					},
+					Ended: true,
+				},
+			}},
+		},
+		"Error": {
+			reason:  "Should record the error failing the operation",
+			handler: handler(http.StatusNotFound),
+			args: args{call: func(ctx context.Context, c Client) error {
+				return c.DeleteKeyValueWithContext(ctx, fakeKey, "")
+			}},
+			want: want{spans: []*fakeSpan{{
+				Name: "appconfig.DeleteKeyValue",
+				Attributes: map[string]interface{}{
+					AttributeKey:        fakeKey,
+					AttributeAttempts:   1,
+					AttributeStatusCode: http.StatusNotFound,
+				},
+				Errors: 1,
+				Ended:  true,
+			}}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+
+			tracer := &fakeTracer{}
+			c := NewClient(server.URL, autorest.NullAuthorizer{},
+				WithTracer(tracer),
+				WithRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}),
+			)
+			_ = tc.args.call(context.Background(), c)
+
+			for _, span := range tc.want.spans {
+				span.Attributes[AttributeEndpoint] = server.URL
+			}
+			if diff := cmp.Diff(tc.want.spans, tracer.spans); diff != "" {
+				t.Errorf("%s: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/stone-payments/appconfig-go-sdk/appconfig/keyvalues"
 )
 
 func main() {
+	ctx := context.Background()
 	endpoint := "https://my-config.azconfig.io"
 	client, err := keyvalues.NewClientCli(endpoint)
 	if err != nil {
@@ -14,14 +16,14 @@ func main() {
 		return
 	}
 
-	kv, err := createKeyValues(client)
+	kv, err := createKeyValues(ctx, client)
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
 	fmt.Printf("KeyValue created. Key: %v\n", *kv.Key)
 
-	list, err := listKeyValues(client)
+	list, err := listKeyValues(ctx, client)
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		return
@@ -32,22 +34,22 @@ func main() {
 	}
 }
 
-func createKeyValues(client keyvalues.Client) (keyvalues.KeyValue, error) {
+func createKeyValues(ctx context.Context, client keyvalues.Client) (keyvalues.KeyValue, error) {
 	args := keyvalues.CreateOrUpdateKeyValueArgs{
 		Key:   "mykey",
 		Label: "mylabel",
 		Value: "myvalue",
 	}
-	kv, err := client.CreateOrUpdateKeyValue(args)
+	kv, err := client.CreateOrUpdateKeyValueWithContext(ctx, args)
 	if err != nil {
 		return keyvalues.KeyValue{}, err
 	}
 	return kv, nil
 }
 
-func listKeyValues(client keyvalues.Client) (keyvalues.KeyValues, error) {
+func listKeyValues(ctx context.Context, client keyvalues.Client) (keyvalues.KeyValues, error) {
 	args := keyvalues.ListKeyValuesArgs{}
-	kvs, err := client.ListKeyValues(args)
+	kvs, err := client.ListKeyValuesWithContext(ctx, args)
 	if err != nil {
 		return keyvalues.KeyValues{}, err
 	}